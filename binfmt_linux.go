@@ -0,0 +1,184 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/containerd/log"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// BinfmtMiscRoot is the default mount point of the binfmt_misc file system.
+const BinfmtMiscRoot = "/proc/sys/fs/binfmt_misc"
+
+// elfMachine identifies an ELF binary by its class, byte order and machine.
+type elfMachine struct {
+	class    byte
+	data     byte
+	eMachine uint16
+}
+
+const (
+	elfClass32 = 1
+	elfClass64 = 2
+	elfDataLSB = 1
+	elfDataMSB = 2
+)
+
+// elfMachineArchs maps ELF binaries to the architecture able to run them.
+var elfMachineArchs = map[elfMachine]string{
+	{elfClass32, elfDataLSB, 3}:   "386",
+	{elfClass64, elfDataLSB, 62}:  "amd64",
+	{elfClass32, elfDataLSB, 40}:  "arm",
+	{elfClass64, elfDataLSB, 183}: "arm64",
+	{elfClass64, elfDataMSB, 21}:  "ppc64",
+	{elfClass64, elfDataLSB, 21}:  "ppc64le",
+	{elfClass64, elfDataMSB, 22}:  "s390x",
+	{elfClass64, elfDataLSB, 243}: "riscv64",
+	{elfClass32, elfDataMSB, 8}:   "mips",
+	{elfClass32, elfDataLSB, 8}:   "mipsle",
+	{elfClass64, elfDataMSB, 8}:   "mips64",
+	{elfClass64, elfDataLSB, 8}:   "mips64le",
+	{elfClass64, elfDataLSB, 258}: "loong64",
+}
+
+// BinfmtMiscPlatforms returns the Linux platforms which the kernel can run
+// through an interpreter registered with binfmt_misc, such as QEMU user
+// emulation. The binfmt_misc file system is read from root, which is usually
+// [BinfmtMiscRoot].
+//
+// Only enabled registrations matching ELF binaries are considered. The
+// platforms are returned in the order of the registrations, without variant.
+func BinfmtMiscPlatforms(root string) ([]specs.Platform, error) {
+	if status, err := os.ReadFile(filepath.Join(root, "status")); err == nil && strings.TrimSpace(string(status)) != "enabled" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var platforms []specs.Platform
+	for _, entry := range entries {
+		switch entry.Name() {
+		case "register", "status":
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		arch, err := readBinfmtMiscEntry(filepath.Join(root, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failure reading binfmt_misc entry %s: %w", entry.Name(), err)
+		}
+		if arch == "" {
+			continue
+		}
+		if !slices.ContainsFunc(platforms, func(p specs.Platform) bool {
+			return p.Architecture == arch
+		}) {
+			platforms = append(platforms, specs.Platform{
+				OS:           "linux",
+				Architecture: arch,
+			})
+		}
+	}
+	return platforms, nil
+}
+
+// readBinfmtMiscEntry returns the architecture of the binaries handled by an
+// enabled binfmt_misc registration, or an empty string if the registration is
+// disabled or does not match ELF binaries.
+func readBinfmtMiscEntry(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var (
+		enabled bool
+		offset  = "0"
+		magic   []byte
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		switch key {
+		case "enabled":
+			enabled = true
+		case "offset":
+			offset = value
+		case "magic":
+			magic, err = hex.DecodeString(value)
+			if err != nil {
+				return "", fmt.Errorf("invalid magic %q: %w", value, errInvalidArgument)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	// The magic must be at the start of an ELF header and include
+	// e_machine, which ends at byte 20.
+	if !enabled || offset != "0" || len(magic) < 20 || string(magic[:4]) != "\x7fELF" {
+		return "", nil
+	}
+	m := elfMachine{
+		class: magic[4],
+		data:  magic[5],
+	}
+	switch m.data {
+	case elfDataLSB:
+		m.eMachine = binary.LittleEndian.Uint16(magic[18:20])
+	case elfDataMSB:
+		m.eMachine = binary.BigEndian.Uint16(magic[18:20])
+	}
+	return elfMachineArchs[m], nil
+}
+
+// DefaultWithEmulation returns the default matcher for the platform, extended
+// with the platforms the kernel can run through binfmt_misc, as found by
+// [BinfmtMiscPlatforms]. Native platforms, matched by [Default], are always
+// preferred over emulated ones, which match any variant of their
+// architecture.
+func DefaultWithEmulation() MatchComparer {
+	return withEmulation(Default(), BinfmtMiscRoot)
+}
+
+func withEmulation(native MatchComparer, root string) MatchComparer {
+	emulated, err := BinfmtMiscPlatforms(root)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.L.Errorf("Error reading binfmt_misc platforms: %v", err)
+	}
+	for i := range emulated {
+		emulated[i].Variant = wildcard
+	}
+	return Or(native, Ordered(emulated...))
+}
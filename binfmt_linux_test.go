@@ -0,0 +1,165 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	qemuAarch64 = `enabled
+interpreter /usr/bin/qemu-aarch64-static
+flags: OCF
+offset 0
+magic 7f454c460201010000000000000000000200b700
+mask ffffffffffffff00fffffffffffffffffeffffff
+`
+	qemuArm = `enabled
+interpreter /usr/bin/qemu-arm-static
+flags: OCF
+offset 0
+magic 7f454c4601010100000000000000000002002800
+mask ffffffffffffff00fffffffffffffffffeffffff
+`
+	qemuS390x = `enabled
+interpreter /usr/bin/qemu-s390x-static
+flags: OCF
+offset 0
+magic 7f454c4602020100000000000000000000020016
+mask ffffffffffffff00fffffffffffffffffffeffff
+`
+	qemuRiscv64Disabled = `disabled
+interpreter /usr/bin/qemu-riscv64-static
+flags: OCF
+offset 0
+magic 7f454c460201010000000000000000000200f300
+mask ffffffffffffff00fffffffffffffffffeffffff
+`
+	wine = `enabled
+interpreter /usr/bin/wine
+flags:
+offset 0
+magic 4d5a
+`
+)
+
+func writeBinfmtMisc(t *testing.T, entries map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range entries {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestBinfmtMiscPlatforms(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		entries  map[string]string
+		expected []specs.Platform
+	}{
+		{
+			name: "qemu",
+			entries: map[string]string{
+				"status":          "enabled\n",
+				"register":        "",
+				"qemu-aarch64":    qemuAarch64,
+				"qemu-arm":        qemuArm,
+				"qemu-riscv64":    qemuRiscv64Disabled,
+				"qemu-s390x":      qemuS390x,
+				"qemu-s390x-copy": qemuS390x,
+				"wine":            wine,
+			},
+			expected: []specs.Platform{
+				{OS: "linux", Architecture: "arm64"},
+				{OS: "linux", Architecture: "arm"},
+				{OS: "linux", Architecture: "s390x"},
+			},
+		},
+		{
+			name: "disabled",
+			entries: map[string]string{
+				"status":       "disabled\n",
+				"qemu-aarch64": qemuAarch64,
+			},
+		},
+		{
+			name: "empty",
+			entries: map[string]string{
+				"status":   "enabled\n",
+				"register": "",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			platforms, err := BinfmtMiscPlatforms(writeBinfmtMisc(t, tc.entries))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(platforms, tc.expected) {
+				t.Errorf("unexpected platforms: %v != %v", platforms, tc.expected)
+			}
+		})
+	}
+
+	if _, err := BinfmtMiscPlatforms(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func TestWithEmulation(t *testing.T) {
+	root := writeBinfmtMisc(t, map[string]string{
+		"qemu-aarch64": qemuAarch64,
+		"qemu-s390x":   qemuS390x,
+	})
+	m := withEmulation(Only(MustParse("linux/amd64")), root)
+
+	for shouldMatch, platforms := range map[bool][]string{
+		true:  {"linux/amd64", "linux/386", "linux/arm64", "linux/arm64/v8.2", "linux/s390x"},
+		false: {"linux/arm/v7", "linux/riscv64", "windows/arm64"},
+	} {
+		for _, s := range platforms {
+			if match := m.Match(MustParse(s)); match != shouldMatch {
+				t.Errorf("Match(%q) should return %v, but returns %v", s, shouldMatch, match)
+			}
+		}
+	}
+
+	platforms, err := ParseAll([]string{"linux/s390x", "linux/arm64", "linux/riscv64", "linux/386", "linux/amd64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.SliceStable(platforms, func(i, j int) bool {
+		return m.Less(platforms[i], platforms[j])
+	})
+	actual := make([]string, len(platforms))
+	for i, p := range platforms {
+		actual[i] = Format(p)
+	}
+	expected := []string{"linux/amd64", "linux/386", "linux/arm64", "linux/s390x", "linux/riscv64"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Wrong platform order:\nExpected: %#v\nActual:   %#v", expected, actual)
+	}
+}
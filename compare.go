@@ -17,8 +17,7 @@
 package platforms
 
 import (
-	"strconv"
-	"strings"
+	"slices"
 
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -31,6 +30,33 @@ type MatchComparer interface {
 	Less(specs.Platform, specs.Platform) bool
 }
 
+// Ranker is implemented by match comparers which rank the platforms they
+// match, such as those returned by [Ordered], [Only], [OnlyOS], [Any] and
+// [Default]. The Less method of these match comparers orders matched
+// platforms by rank, before the platforms they do not match.
+type Ranker interface {
+	// Rank returns the rank of the platform and true if it is matched.
+	// Lower ranks are preferred.
+	Rank(specs.Platform) (int, bool)
+}
+
+// featureRanks bounds the number of OS features considered when ranking
+// platforms matched by the same matcher, which prefers the platforms with the
+// most features.
+const featureRanks = 256
+
+// featureRank returns the rank of the platform among the platforms matched by
+// the same matcher.
+func featureRank(platform specs.Platform) int {
+	return featureRanks - 1 - min(len(platform.OSFeatures), featureRanks-1)
+}
+
+// lessRank returns true if the first ranked platform is preferred: matched
+// platforms are preferred to unmatched ones, and lower ranks to higher ones.
+func lessRank(r1 int, ok1 bool, r2 int, ok2 bool) bool {
+	return ok1 && (!ok2 || r1 < r2)
+}
+
 type platformVersions struct {
 	major []int
 	minor []int
@@ -63,79 +89,40 @@ var arm64variantToVersion = map[string]platformVersions{
 // objects to try matching for the given platform object (see platforms.Only).
 func platformVector(platform specs.Platform) []specs.Platform {
 	vector := []specs.Platform{platform}
+	if platform.Architecture == wildcard || platform.Variant == wildcard {
+		// A wildcard already covers every fallback.
+		return vector
+	}
+	return appendLadder(vector, platform, []string{platform.Architecture})
+}
 
-	switch platform.Architecture {
-	case "amd64":
-		if amd64Version, err := strconv.Atoi(strings.TrimPrefix(platform.Variant, "v")); err == nil && amd64Version > 1 {
-			for amd64Version--; amd64Version >= 1; amd64Version-- {
-				vector = append(vector, specs.Platform{
-					Architecture: platform.Architecture,
-					OS:           platform.OS,
-					OSVersion:    platform.OSVersion,
-					OSFeatures:   platform.OSFeatures,
-					Variant:      "v" + strconv.Itoa(amd64Version),
-				})
-			}
-		}
-		vector = append(vector, specs.Platform{
-			Architecture: "386",
+// appendLadder appends the platforms of the ladder registered for the
+// architecture of the platform, skipping those already in the vector. The
+// ladders of other architectures in the ladder are expanded in turn, unless
+// already in archs.
+func appendLadder(vector []specs.Platform, platform specs.Platform, archs []string) []specs.Platform {
+	ladder := lookupLadder(platform.Architecture)
+	if ladder == nil {
+		return vector
+	}
+	for _, step := range ladder(platform.Variant) {
+		p := specs.Platform{
+			Architecture: step.Architecture,
 			OS:           platform.OS,
 			OSVersion:    platform.OSVersion,
 			OSFeatures:   platform.OSFeatures,
-		})
-	case "arm":
-		if armVersion, err := strconv.Atoi(strings.TrimPrefix(platform.Variant, "v")); err == nil && armVersion > 5 {
-			for armVersion--; armVersion >= 5; armVersion-- {
-				vector = append(vector, specs.Platform{
-					Architecture: platform.Architecture,
-					OS:           platform.OS,
-					OSVersion:    platform.OSVersion,
-					OSFeatures:   platform.OSFeatures,
-					Variant:      "v" + strconv.Itoa(armVersion),
-				})
-			}
-		}
-	case "arm64":
-		variant := platform.Variant
-		if variant == "" {
-			variant = "v8"
+			Variant:      step.Variant,
 		}
-
-		vector = []specs.Platform{} // Reset vector, the first variant will be added in loop.
-		arm64Versions, ok := arm64variantToVersion[variant]
-		if !ok {
-			break
+		if slices.ContainsFunc(vector, func(v specs.Platform) bool {
+			return v.Architecture == p.Architecture && v.Variant == p.Variant
+		}) {
+			continue
 		}
-		for i, major := range arm64Versions.major {
-			for minor := arm64Versions.minor[i]; minor >= 0; minor-- {
-				arm64Variant := "v" + strconv.Itoa(major) + "." + strconv.Itoa(minor)
-				if minor == 0 {
-					arm64Variant = "v" + strconv.Itoa(major)
-				}
-				vector = append(vector, specs.Platform{
-					Architecture: "arm64",
-					OS:           platform.OS,
-					OSVersion:    platform.OSVersion,
-					OSFeatures:   platform.OSFeatures,
-					Variant:      arm64Variant,
-				})
-			}
-		}
-
-		// All arm64/v8.x and arm64/v9.x are compatible with arm/v8 (32-bits) and below.
-		// There's no arm64 v9 variant, so it's normalized to v8.
-		if strings.HasPrefix(variant, "v8") || strings.HasPrefix(variant, "v9") {
-			variant = "v8"
+		vector = append(vector, p)
+		if !slices.Contains(archs, p.Architecture) {
+			vector = appendLadder(vector, p, append(slices.Clip(archs), p.Architecture))
 		}
-		vector = append(vector, platformVector(specs.Platform{
-			Architecture: "arm",
-			OS:           platform.OS,
-			OSVersion:    platform.OSVersion,
-			OSFeatures:   platform.OSFeatures,
-			Variant:      variant,
-		})...)
 	}
-
 	return vector
 }
 
@@ -148,6 +135,16 @@ func platformVector(platform specs.Platform) []specs.Platform {
 // For arm/v7, will also match arm/v6 and arm/v5
 // For arm/v6, will also match arm/v5
 // For amd64, will also match 386
+// For riscv64/rva23u64, will also match riscv64/rva22u64 and riscv64
+// For riscv64/rva22u64, will also match riscv64
+// For ppc64le/power10, will also match ppc64le/power9 and ppc64le
+// For ppc64le/power9, will also match ppc64le
+// For s390x/z16, will also match s390x/z15, s390x/z14 and s390x
+// For s390x/z15, will also match s390x/z14 and s390x
+// For s390x/z14, will also match s390x
+//
+// The platforms matched for other architectures are described by the ladders
+// registered with [RegisterLadder], and returned by [Vector].
 func Only(platform specs.Platform) MatchComparer {
 	return Ordered(platformVector(Normalize(platform))...)
 }
@@ -184,7 +181,7 @@ type onlyOSComparer struct {
 
 func (c onlyOSComparer) matchOS(platform specs.Platform) bool {
 	normalized := Normalize(platform)
-	if c.platform.OS != normalized.OS {
+	if !matchComponent(c.platform.OS, normalized.OS) {
 		return false
 	}
 	if c.osvM != nil {
@@ -192,46 +189,29 @@ func (c onlyOSComparer) matchOS(platform specs.Platform) bool {
 			return false
 		}
 	}
-	if len(normalized.OSFeatures) > 0 {
-		if len(c.platform.OSFeatures) < len(normalized.OSFeatures) {
-			return false
-		}
-		j := 0
-		for _, feature := range normalized.OSFeatures {
-			found := false
-			for ; j < len(c.platform.OSFeatures); j++ {
-				if feature == c.platform.OSFeatures[j] {
-					found = true
-					j++
-					break
-				}
-				if feature < c.platform.OSFeatures[j] {
-					return false
-				}
-			}
-			if !found {
-				return false
-			}
-		}
-	}
-	return true
+	return isOSFeaturesSubset(c.platform.OSFeatures, normalized.OSFeatures)
 }
 
 func (c onlyOSComparer) Match(platform specs.Platform) bool {
 	return c.matchOS(platform)
 }
 
-func (c onlyOSComparer) Less(p1, p2 specs.Platform) bool {
-	p1m := c.matchOS(p1)
-	p2m := c.matchOS(p2)
-	if p1m && !p2m {
-		return true
+func (c onlyOSComparer) Rank(platform specs.Platform) (int, bool) {
+	if !c.matchOS(platform) {
+		return 0, false
 	}
-	if !p1m {
-		return false
+	if r, ok := c.archOrder.Rank(platform); ok {
+		return r, true
 	}
-	// Both match — rank by architecture preference
-	return c.archOrder.Less(p1, p2)
+	// Platforms with another architecture are ranked after the architectures
+	// preferred by the default platform resolution logic.
+	return len(c.archOrder.matchers) * featureRanks, true
+}
+
+func (c onlyOSComparer) Less(p1, p2 specs.Platform) bool {
+	r1, ok1 := c.Rank(p1)
+	r2, ok2 := c.Rank(p2)
+	return lessRank(r1, ok1, r2, ok2)
 }
 
 // OnlyStrict returns a match comparer for a single platform.
@@ -270,6 +250,57 @@ func Any(platforms ...specs.Platform) MatchComparer {
 	}
 }
 
+// Exclude returns a platform MatchComparer which matches any of the included
+// platforms, ordered as with [Ordered], unless the platform also matches one
+// of the excluded platforms. When no platforms are included, every platform
+// which is not excluded matches.
+//
+// Excluded platforms are matched strictly, as with [NewMatcher].
+func Exclude(include, exclude []specs.Platform) MatchComparer {
+	c := excludePlatformComparer{
+		include: All,
+	}
+	if len(include) > 0 {
+		c.include = Ordered(include...)
+	}
+	for _, p := range exclude {
+		c.exclude = append(c.exclude, NewMatcher(p))
+	}
+	return c
+}
+
+// And returns a platform MatchComparer which matches platforms matched by all
+// of the provided matchers. Matching platforms are ordered by the first
+// matcher implementing [MatchComparer] which orders them differently.
+//
+// And with no matchers matches all platforms.
+func And(matchers ...Matcher) MatchComparer {
+	return andPlatformComparer{
+		matchers: matchers,
+	}
+}
+
+// Or returns a platform MatchComparer which matches platforms matched by any
+// of the provided matchers. Matching platforms are ordered by the first
+// matcher which matches them, as with [Ordered]. Platforms first matched by
+// the same matcher are ordered by that matcher if it implements
+// [MatchComparer].
+//
+// Or with no matchers matches no platforms.
+func Or(matchers ...Matcher) MatchComparer {
+	return orPlatformComparer{
+		matchers: matchers,
+	}
+}
+
+// Not returns a platform MatchComparer which matches platforms not matched by
+// the provided matcher. Matching platforms are not ordered.
+func Not(m Matcher) MatchComparer {
+	return notPlatformComparer{
+		matcher: m,
+	}
+}
+
 // All is a platform MatchComparer which matches all platforms
 // with preference for ordering.
 var All MatchComparer = allPlatformComparer{}
@@ -287,29 +318,26 @@ func (c orderedPlatformComparer) Match(platform specs.Platform) bool {
 	return false
 }
 
-func (c orderedPlatformComparer) Less(p1 specs.Platform, p2 specs.Platform) bool {
-	for _, m := range c.matchers {
-		p1m := m.Match(p1)
-		p2m := m.Match(p2)
-		if p1m && !p2m {
-			return true
-		}
-		if p1m || p2m {
-			if p1m && p2m {
-				// Prefer one with most matching features
-				if len(p1.OSFeatures) != len(p2.OSFeatures) {
-					return len(p1.OSFeatures) > len(p2.OSFeatures)
-				}
-			}
-			return false
+func (c orderedPlatformComparer) Rank(platform specs.Platform) (int, bool) {
+	for i, m := range c.matchers {
+		if m.Match(platform) {
+			// Prefer one with most matching features
+			return i*featureRanks + featureRank(platform), true
 		}
 	}
-	if len(p1.OSFeatures) > 0 || len(p2.OSFeatures) > 0 {
+	return 0, false
+}
+
+func (c orderedPlatformComparer) Less(p1 specs.Platform, p2 specs.Platform) bool {
+	r1, ok1 := c.Rank(p1)
+	r2, ok2 := c.Rank(p2)
+	if !ok1 && !ok2 && (len(p1.OSFeatures) > 0 || len(p2.OSFeatures) > 0) {
+		// If neither match and has features, strip features and compare
 		p1.OSFeatures = nil
 		p2.OSFeatures = nil
 		return c.Less(p1, p2)
 	}
-	return false
+	return lessRank(r1, ok1, r2, ok2)
 }
 
 type anyPlatformComparer struct {
@@ -325,32 +353,124 @@ func (c anyPlatformComparer) Match(platform specs.Platform) bool {
 	return false
 }
 
+func (c anyPlatformComparer) Rank(platform specs.Platform) (int, bool) {
+	if !c.Match(platform) {
+		return 0, false
+	}
+	// Prefer one with most matching features
+	return featureRank(platform), true
+}
+
 func (c anyPlatformComparer) Less(p1, p2 specs.Platform) bool {
-	var p1m, p2m bool
+	r1, ok1 := c.Rank(p1)
+	r2, ok2 := c.Rank(p2)
+	if !ok1 && !ok2 && (len(p1.OSFeatures) > 0 || len(p2.OSFeatures) > 0) {
+		// If neither match and has features, strip features and compare
+		p1.OSFeatures = nil
+		p2.OSFeatures = nil
+		return c.Less(p1, p2)
+	}
+	return lessRank(r1, ok1, r2, ok2)
+}
+
+type excludePlatformComparer struct {
+	include MatchComparer
+	exclude []Matcher
+}
+
+func (c excludePlatformComparer) Match(platform specs.Platform) bool {
+	for _, m := range c.exclude {
+		if m.Match(platform) {
+			return false
+		}
+	}
+	return c.include.Match(platform)
+}
+
+func (c excludePlatformComparer) Less(p1, p2 specs.Platform) bool {
+	p1m, p2m := c.Match(p1), c.Match(p2)
+	if p1m && p2m {
+		return c.include.Less(p1, p2)
+	}
+	return p1m && !p2m
+}
+
+type andPlatformComparer struct {
+	matchers []Matcher
+}
+
+func (c andPlatformComparer) Match(platform specs.Platform) bool {
+	for _, m := range c.matchers {
+		if !m.Match(platform) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c andPlatformComparer) Less(p1, p2 specs.Platform) bool {
+	p1m, p2m := c.Match(p1), c.Match(p2)
+	if !p1m || !p2m {
+		return p1m && !p2m
+	}
 	for _, m := range c.matchers {
-		if !p1m && m.Match(p1) {
-			p1m = true
+		mc, ok := m.(MatchComparer)
+		if !ok {
+			continue
 		}
-		if !p2m && m.Match(p2) {
-			p2m = true
+		if mc.Less(p1, p2) {
+			return true
 		}
-		if p1m && p2m {
-			if len(p1.OSFeatures) != len(p2.OSFeatures) {
-				return len(p1.OSFeatures) > len(p2.OSFeatures)
-			}
-			break
+		if mc.Less(p2, p1) {
+			return false
 		}
 	}
+	return false
+}
 
-	// If neither match and has features, strip features and compare
-	if !p1m && !p2m && (len(p1.OSFeatures) > 0 || len(p2.OSFeatures) > 0) {
-		p1.OSFeatures = nil
-		p2.OSFeatures = nil
-		return c.Less(p1, p2)
+type orPlatformComparer struct {
+	matchers []Matcher
+}
+
+func (c orPlatformComparer) Match(platform specs.Platform) bool {
+	return c.index(platform) >= 0
+}
+
+// index returns the index of the first matcher matching the platform or -1
+// if none match.
+func (c orPlatformComparer) index(platform specs.Platform) int {
+	for i, m := range c.matchers {
+		if m.Match(platform) {
+			return i
+		}
 	}
+	return -1
+}
 
-	// If one matches, and the other does, sort match first
-	return p1m && !p2m
+func (c orPlatformComparer) Less(p1, p2 specs.Platform) bool {
+	i1, i2 := c.index(p1), c.index(p2)
+	if i1 < 0 || i2 < 0 {
+		return i1 >= 0 && i2 < 0
+	}
+	if i1 != i2 {
+		return i1 < i2
+	}
+	if mc, ok := c.matchers[i1].(MatchComparer); ok {
+		return mc.Less(p1, p2)
+	}
+	return false
+}
+
+type notPlatformComparer struct {
+	matcher Matcher
+}
+
+func (c notPlatformComparer) Match(platform specs.Platform) bool {
+	return !c.matcher.Match(platform)
+}
+
+func (c notPlatformComparer) Less(p1, p2 specs.Platform) bool {
+	return c.Match(p1) && !c.Match(p2)
 }
 
 type allPlatformComparer struct{}
@@ -359,6 +479,10 @@ func (allPlatformComparer) Match(specs.Platform) bool {
 	return true
 }
 
+func (allPlatformComparer) Rank(specs.Platform) (int, bool) {
+	return 0, true
+}
+
 func (allPlatformComparer) Less(specs.Platform, specs.Platform) bool {
 	return false
 }
@@ -19,7 +19,10 @@ package platforms
 import (
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 func TestOnly(t *testing.T) {
@@ -298,6 +301,107 @@ func TestOnly(t *testing.T) {
 				},
 			},
 		},
+		{
+			platform: "linux/ppc64le",
+			matches: map[bool][]string{
+				true: {
+					"linux/ppc64le",
+					"linux/ppc64el",
+					"linux/ppc64le/power8",
+				},
+				false: {
+					"linux/ppc64le/power9",
+					"linux/ppc64le/power10",
+					"linux/ppc64",
+				},
+			},
+		},
+		{
+			platform: "linux/ppc64le/power10",
+			matches: map[bool][]string{
+				true: {
+					"linux/ppc64le",
+					"linux/ppc64le/power8",
+					"linux/ppc64le/power9",
+					"linux/powerpc64le/power10",
+				},
+				false: {
+					"linux/ppc64le/power11",
+					"linux/ppc64",
+				},
+			},
+		},
+		{
+			platform: "linux/s390x/z16",
+			matches: map[bool][]string{
+				true: {
+					"linux/s390x",
+					"linux/s390x/z13",
+					"linux/s390x/z14",
+					"linux/s390x/z15",
+					"linux/s390x/z16",
+				},
+				false: {
+					"linux/s390x/z17",
+					"linux/s390",
+				},
+			},
+		},
+		{
+			platform: "linux/s390x/z14",
+			matches: map[bool][]string{
+				true: {
+					"linux/s390x",
+					"linux/s390x/z14",
+				},
+				false: {
+					"linux/s390x/z15",
+					"linux/s390x/z16",
+				},
+			},
+		},
+		{
+			platform: "linux/riscv64",
+			matches: map[bool][]string{
+				true: {
+					"linux/riscv64",
+					"linux/riscv64/rva20u64",
+				},
+				false: {
+					"linux/riscv64/rva22u64",
+					"linux/riscv64/rva23u64",
+					"linux/amd64",
+				},
+			},
+		},
+		{
+			platform: "linux/riscv64/rva22",
+			matches: map[bool][]string{
+				true: {
+					"linux/riscv64",
+					"linux/riscv64/rva20u64",
+					"linux/riscv64/rva22u64",
+				},
+				false: {
+					"linux/riscv64/rva23u64",
+				},
+			},
+		},
+		{
+			platform: "linux/riscv64/rva23u64",
+			matches: map[bool][]string{
+				true: {
+					"linux/riscv64",
+					"linux/riscv64/rva20",
+					"linux/riscv64/rva22u64",
+					"linux/riscv64/rva23u64",
+				},
+				false: {
+					"linux/riscv64/rva24u64",
+					"linux/arm64",
+				},
+			},
+		},
 	} {
 		testcase := tc
 		t.Run(testcase.platform, func(t *testing.T) {
@@ -321,6 +425,44 @@ func TestOnly(t *testing.T) {
 	}
 }
 
+func TestOnlyRISCV64Order(t *testing.T) {
+	m := Only(MustParse("linux/riscv64/rva23u64"))
+	platforms, err := ParseAll([]string{"linux/riscv64", "linux/riscv64/rva22u64", "linux/amd64", "linux/riscv64/rva23u64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.SliceStable(platforms, func(i, j int) bool {
+		return m.Less(platforms[i], platforms[j])
+	})
+	actual := make([]string, len(platforms))
+	for i, p := range platforms {
+		actual[i] = Format(p)
+	}
+	expected := []string{"linux/riscv64/rva23u64", "linux/riscv64/rva22u64", "linux/riscv64", "linux/amd64"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Wrong platform order:\nExpected: %#v\nActual:   %#v", expected, actual)
+	}
+}
+
+func TestOnlyPPC64LEOrder(t *testing.T) {
+	m := Only(MustParse("linux/ppc64le/power10"))
+	platforms, err := ParseAll([]string{"linux/ppc64le", "linux/ppc64le/power9", "linux/ppc64le/power10", "linux/s390x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.SliceStable(platforms, func(i, j int) bool {
+		return m.Less(platforms[i], platforms[j])
+	})
+	actual := make([]string, len(platforms))
+	for i, p := range platforms {
+		actual[i] = Format(p)
+	}
+	expected := []string{"linux/ppc64le/power10", "linux/ppc64le/power9", "linux/ppc64le", "linux/s390x"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Wrong platform order:\nExpected: %#v\nActual:   %#v", expected, actual)
+	}
+}
+
 func TestOnlyStrict(t *testing.T) {
 	for _, tc := range []struct {
 		platform string
@@ -805,3 +947,446 @@ func TestCompareOSFeatures(t *testing.T) {
 		})
 	}
 }
+
+func TestWildcard(t *testing.T) {
+	for _, tc := range []struct {
+		platform string
+		matches  map[bool][]string
+	}{
+		{
+			platform: "linux/*",
+			matches: map[bool][]string{
+				true: {
+					"linux/amd64",
+					"linux/amd64/v3",
+					"linux/arm/v6",
+					"linux/arm64",
+					"linux/s390x",
+				},
+				false: {
+					"windows/amd64",
+					"darwin/arm64",
+				},
+			},
+		},
+		{
+			platform: "*/arm64",
+			matches: map[bool][]string{
+				true: {
+					"linux/arm64",
+					"linux/aarch64",
+					"windows/arm64",
+					"darwin/arm64/v8",
+				},
+				false: {
+					"linux/amd64",
+					"linux/arm64/v8.2",
+					"linux/riscv64",
+				},
+			},
+		},
+		{
+			platform: "linux/arm/*",
+			matches: map[bool][]string{
+				true: {
+					"linux/arm",
+					"linux/arm/v5",
+					"linux/arm/v8",
+					"linux/armel",
+				},
+				false: {
+					"linux/arm64",
+					"windows/arm/v7",
+				},
+			},
+		},
+		{
+			platform: "*/*",
+			matches: map[bool][]string{
+				true: {
+					"linux/amd64",
+					"windows(10.0.17763)/amd64",
+					"darwin/arm64",
+					"linux/arm/v5",
+				},
+			},
+		},
+	} {
+		testcase := tc
+		t.Run(testcase.platform, func(t *testing.T) {
+			p, err := Parse(testcase.platform)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, stc := range []struct {
+				name string
+				m    Matcher
+			}{
+				{name: "matcher", m: NewMatcher(p)},
+				{name: "only", m: Only(p)},
+				{name: "ordered", m: Ordered(p)},
+				{name: "any", m: Any(p)},
+			} {
+				for shouldMatch, platforms := range testcase.matches {
+					for _, matchPlatform := range platforms {
+						mp, err := Parse(matchPlatform)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if match := stc.m.Match(mp); shouldMatch != match {
+							t.Errorf("%s(%q).Match(%q) should return %v, but returns %v", stc.name, testcase.platform, matchPlatform, shouldMatch, match)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWildcardOrdered(t *testing.T) {
+	m := Ordered(MustParse("linux/arm64"), MustParse("linux/*"))
+	platforms, err := ParseAll([]string{"windows/amd64", "linux/amd64", "linux/arm64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.SliceStable(platforms, func(i, j int) bool {
+		return m.Less(platforms[i], platforms[j])
+	})
+	actual := make([]string, len(platforms))
+	for i, p := range platforms {
+		actual[i] = Format(p)
+	}
+	expected := []string{"linux/arm64", "linux/amd64", "windows/amd64"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Wrong platform order:\nExpected: %#v\nActual:   %#v", expected, actual)
+	}
+}
+
+func TestExclude(t *testing.T) {
+	for _, tc := range []struct {
+		filters   []string
+		matches   map[bool][]string
+		platforms []string
+		expected  []string
+	}{
+		{
+			filters: []string{"!windows"},
+			matches: map[bool][]string{
+				true: {
+					"linux/amd64",
+					"linux/arm64",
+					"darwin/arm64",
+				},
+				false: {
+					"windows/amd64",
+					"windows(10.0.17763)/arm64",
+				},
+			},
+		},
+		{
+			filters: []string{"linux/*", "!linux/386"},
+			matches: map[bool][]string{
+				true: {
+					"linux/amd64",
+					"linux/arm/v7",
+				},
+				false: {
+					"linux/386",
+					"windows/amd64",
+				},
+			},
+		},
+		{
+			filters: []string{"linux/arm64", "linux/amd64", "!linux/arm64/v8.2"},
+			matches: map[bool][]string{
+				true: {
+					"linux/arm64",
+					"linux/amd64",
+				},
+				false: {
+					"linux/arm64/v8.2",
+					"linux/386",
+				},
+			},
+			platforms: []string{"linux/386", "linux/arm64/v8.2", "linux/amd64", "linux/arm64"},
+			expected:  []string{"linux/arm64", "linux/amd64", "linux/386", "linux/arm64/v8.2"},
+		},
+		{
+			filters: []string{"!linux/arm64", "!linux/arm"},
+			matches: map[bool][]string{
+				true: {
+					"linux/amd64",
+					"windows/arm64",
+				},
+				false: {
+					"linux/arm64",
+					"linux/arm64/v8.2",
+					"linux/arm64/v9",
+					"linux/arm/v7",
+					"linux/arm/v6",
+				},
+			},
+		},
+		{
+			filters: []string{"!arm64", "!linux/arm/v6"},
+			matches: map[bool][]string{
+				true: {
+					"linux/amd64",
+					"linux/arm/v7",
+				},
+				false: {
+					"darwin/arm64",
+					"linux/arm64/v8.4",
+					"linux/arm/v6",
+				},
+			},
+		},
+	} {
+		testcase := tc
+		t.Run(strings.Join(testcase.filters, ","), func(t *testing.T) {
+			include, exclude, err := ParseFilters(testcase.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := Exclude(include, exclude)
+			for shouldMatch, platforms := range testcase.matches {
+				for _, matchPlatform := range platforms {
+					mp, err := Parse(matchPlatform)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if match := m.Match(mp); shouldMatch != match {
+						t.Errorf("Exclude(%q).Match(%q) should return %v, but returns %v", testcase.filters, matchPlatform, shouldMatch, match)
+					}
+				}
+			}
+			if testcase.platforms == nil {
+				return
+			}
+
+			platforms, err := ParseAll(testcase.platforms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.SliceStable(platforms, func(i, j int) bool {
+				return m.Less(platforms[i], platforms[j])
+			})
+			actual := make([]string, len(platforms))
+			for i, p := range platforms {
+				actual[i] = Format(p)
+			}
+			if !reflect.DeepEqual(testcase.expected, actual) {
+				t.Errorf("Wrong platform order:\nExpected: %#v\nActual:   %#v", testcase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	host := MustParse("linux/arm/v7")
+	v5 := specs.Platform{OS: "linux", Architecture: "arm", Variant: "v5"}
+
+	for _, tc := range []struct {
+		name      string
+		mc        MatchComparer
+		matches   map[bool][]string
+		platforms []string
+		expected  []string
+	}{
+		{
+			name: "only and not",
+			mc:   And(Only(host), Not(NewMatcher(v5))),
+			matches: map[bool][]string{
+				true: {
+					"linux/arm/v7",
+					"linux/arm/v6",
+				},
+				false: {
+					"linux/arm/v5",
+					"linux/arm64",
+					"windows/arm/v7",
+				},
+			},
+			platforms: []string{"linux/arm/v5", "linux/arm/v6", "linux/amd64", "linux/arm/v7"},
+			expected:  []string{"linux/arm/v7", "linux/arm/v6", "linux/arm/v5", "linux/amd64"},
+		},
+		{
+			name: "or",
+			mc:   Or(NewMatcher(MustParse("linux/amd64")), Only(MustParse("linux/arm64"))),
+			matches: map[bool][]string{
+				true: {
+					"linux/amd64",
+					"linux/arm64",
+					"linux/arm/v7",
+				},
+				false: {
+					"linux/386",
+					"windows/amd64",
+				},
+			},
+			platforms: []string{"linux/arm/v7", "windows/amd64", "linux/arm64", "linux/amd64"},
+			expected:  []string{"linux/amd64", "linux/arm64", "linux/arm/v7", "windows/amd64"},
+		},
+		{
+			name: "not",
+			mc:   Not(Only(MustParse("linux/amd64"))),
+			matches: map[bool][]string{
+				true: {
+					"linux/arm64",
+					"windows/amd64",
+				},
+				false: {
+					"linux/amd64",
+					"linux/386",
+				},
+			},
+			platforms: []string{"linux/amd64", "linux/arm64", "linux/386", "windows/amd64"},
+			expected:  []string{"linux/arm64", "windows/amd64", "linux/amd64", "linux/386"},
+		},
+		{
+			name: "empty and",
+			mc:   And(),
+			matches: map[bool][]string{
+				true: {
+					"linux/amd64",
+				},
+			},
+		},
+		{
+			name: "empty or",
+			mc:   Or(),
+			matches: map[bool][]string{
+				false: {
+					"linux/amd64",
+				},
+			},
+		},
+	} {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			for shouldMatch, platforms := range testcase.matches {
+				for _, matchPlatform := range platforms {
+					mp, err := Parse(matchPlatform)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if match := testcase.mc.Match(mp); shouldMatch != match {
+						t.Errorf("Match(%q) should return %v, but returns %v", matchPlatform, shouldMatch, match)
+					}
+				}
+			}
+			if testcase.platforms == nil {
+				return
+			}
+
+			platforms, err := ParseAll(testcase.platforms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.SliceStable(platforms, func(i, j int) bool {
+				return testcase.mc.Less(platforms[i], platforms[j])
+			})
+			actual := make([]string, len(platforms))
+			for i, p := range platforms {
+				actual[i] = Format(p)
+			}
+			if !reflect.DeepEqual(testcase.expected, actual) {
+				t.Errorf("Wrong platform order:\nExpected: %#v\nActual:   %#v", testcase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	platforms := []specs.Platform{
+		MustParse("linux/amd64"),
+		MustParse("linux/amd64/v3"),
+		MustParse("linux/386"),
+		MustParse("linux/arm64"),
+		MustParse("linux/arm64/v8.2"),
+		MustParse("linux/arm/v7"),
+		MustParse("linux(+musl)/arm64"),
+		MustParse("linux(+musl+fips)/arm64"),
+		MustParse("freebsd/amd64"),
+		MustParse("windows/amd64"),
+		MustParse("windows(10.0.17763.5830)/amd64"),
+		MustParse("windows(10.0.20348.2527)/amd64"),
+		MustParse("windows(10.0.20348.999)/amd64"),
+		MustParse("windows(10.0.26100.1742)/amd64"),
+	}
+
+	for _, tc := range []struct {
+		name string
+		mc   MatchComparer
+	}{
+		{"only", Only(MustParse("linux(+musl+fips)/arm64/v8.2"))},
+		{"only strict", OnlyStrict(MustParse("linux/amd64"))},
+		{"ordered", Ordered(MustParse("linux/arm64"), MustParse("linux(+musl)/arm64"), MustParse("windows/amd64"))},
+		{"only os", OnlyOS(MustParse("linux(+musl)/arm64"))},
+		{"any", Any(MustParse("linux(+musl+fips)/arm64"), MustParse("linux/amd64"))},
+		{"windows", &windowsMatchComparer{Matcher: NewMatcher(MustParse("windows(10.0.26100)/amd64"))}},
+		{"all", All},
+		{"compiled", Compile(Only(MustParse("linux(+musl+fips)/arm64/v8.2")))},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, ok := tc.mc.(Ranker)
+			if !ok {
+				t.Fatalf("%T does not implement Ranker", tc.mc)
+			}
+			for _, p1 := range platforms {
+				r1, ok1 := r.Rank(p1)
+				if match := tc.mc.Match(p1); ok1 != match {
+					t.Errorf("Rank(%q) returned %t, but Match returns %t", FormatAll(p1), ok1, match)
+				}
+				for _, p2 := range platforms {
+					r2, ok2 := r.Rank(p2)
+					if !ok1 && !ok2 {
+						// Less orders unmatched platforms without their features
+						continue
+					}
+					if expected, actual := lessRank(r1, ok1, r2, ok2), tc.mc.Less(p1, p2); expected != actual {
+						t.Errorf("Less(%q, %q) = %t, but ranks are %d (%t) and %d (%t)", FormatAll(p1), FormatAll(p2), actual, r1, ok1, r2, ok2)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRankOrder(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		r        Ranker
+		expected []string
+	}{
+		{
+			name:     "only",
+			r:        Only(MustParse("linux/arm64/v8.2")).(Ranker),
+			expected: []string{"linux/arm64/v8.2", "linux/arm64/v8.1", "linux/arm64", "linux/arm/v8", "linux/arm/v7"},
+		},
+		{
+			name:     "only os",
+			r:        OnlyOS(MustParse("linux/amd64")).(Ranker),
+			expected: []string{"linux/amd64", "linux/arm64"},
+		},
+		{
+			name:     "windows",
+			r:        &windowsMatchComparer{Matcher: NewMatcher(MustParse("windows(10.0.26100)/amd64"))},
+			expected: []string{"windows(10.0.26100.1742)/amd64", "windows(10.0.20348.2527)/amd64", "windows(10.0.20348.999)/amd64", "windows/amd64"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			prev := -1
+			for _, s := range tc.expected {
+				rank, ok := tc.r.Rank(MustParse(s))
+				if !ok {
+					t.Fatalf("expected %q to be ranked", s)
+				}
+				if rank <= prev {
+					t.Errorf("expected rank of %q (%d) to be higher than %d", s, rank, prev)
+				}
+				prev = rank
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"sync"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// maxCompiledRanks bounds the number of ranks memoized by a compiled
+// comparer. The memo is reset when it is full.
+const maxCompiledRanks = 4096
+
+// Compile returns a MatchComparer equivalent to m, precompiled for matching
+// and sorting many platforms, such as the manifests of a large image index.
+//
+// The OS, architecture, variant and OS features of the matched platforms are
+// interned, so Match does not allocate for platforms in canonical form, and
+// Less computes the rank of each platform once and memoizes it.
+//
+// Compile supports the match comparers returned by [Only], [OnlyStrict],
+// [Ordered] and, outside of Windows, [Default]. Other match comparers are
+// returned unchanged.
+func Compile(m MatchComparer) MatchComparer {
+	ordered, ok := m.(orderedPlatformComparer)
+	if !ok {
+		return m
+	}
+
+	c := &compiledComparer{
+		os:       map[string]int32{},
+		arch:     map[string]int32{},
+		variant:  map[string]int32{},
+		features: map[string]uint64{},
+		ranks:    map[compiledPlatform]compiledRank{},
+	}
+	for _, om := range ordered.matchers {
+		entry, ok := c.compileMatcher(om)
+		if !ok {
+			return m
+		}
+		c.windows = c.windows || entry.osvM != nil
+		c.entries = append(c.entries, entry)
+	}
+	return c
+}
+
+// compiledEntry is a compiled platform matcher, as returned by [NewMatcher].
+type compiledEntry struct {
+	os, arch, variant int32
+
+	// features is the set of features a matched platform may have.
+	features uint64
+
+	// osvM matches the OS version on Windows.
+	osvM osVerMatcher
+
+	// stripWin32k ignores the win32k feature of matched platforms.
+	stripWin32k bool
+}
+
+// compiledPlatform is the interned form of a platform to match.
+type compiledPlatform struct {
+	os, arch, variant int32
+
+	// features and windowsFeatures are the set of features of the platform,
+	// including or without its win32k feature. The unknownFeature bit is
+	// set for features not in any compiled entry.
+	features        uint64
+	windowsFeatures uint64
+
+	// nfeatures is the number of features, as ordered by [Ordered].
+	nfeatures int
+
+	// osVersion is only set when a compiled entry matches Windows versions.
+	osVersion string
+}
+
+// compiledRank is the rank of a platform in the compiled entries, which is
+// the index of the first entry matching it, with and without its features.
+type compiledRank struct {
+	index, strippedIndex int
+	nfeatures            int
+}
+
+const (
+	// wildcardID is the interned ID of a wildcard component.
+	wildcardID = -1
+
+	// unknownFeature is the bit of features unknown to the compiled entries.
+	unknownFeature = uint64(1) << 63
+)
+
+type compiledComparer struct {
+	os, arch, variant map[string]int32
+	features          map[string]uint64
+	entries           []compiledEntry
+	windows           bool
+
+	mu    sync.Mutex
+	ranks map[compiledPlatform]compiledRank
+}
+
+func (c *compiledComparer) compileMatcher(m Matcher) (compiledEntry, bool) {
+	switch m := m.(type) {
+	case *matcher:
+		entry := compiledEntry{
+			os:      intern(c.os, m.OS),
+			arch:    intern(c.arch, m.Architecture),
+			variant: intern(c.variant, m.Variant),
+			osvM:    m.osvM,
+		}
+		if m.Architecture == wildcard && m.Variant == "" {
+			entry.variant = wildcardID
+		}
+		for _, f := range m.OSFeatures {
+			bit, ok := c.features[f]
+			if !ok {
+				if len(c.features) == 63 {
+					// The bitset is full.
+					return compiledEntry{}, false
+				}
+				bit = uint64(1) << len(c.features)
+				c.features[f] = bit
+			}
+			entry.features |= bit
+		}
+		return entry, true
+	case windowsStripFeaturesMatcher:
+		entry, ok := c.compileMatcher(m.Matcher)
+		entry.stripWin32k = true
+		return entry, ok
+	case *windowsMatchComparer:
+		return c.compileMatcher(m.Matcher)
+	}
+	return compiledEntry{}, false
+}
+
+// intern returns the ID of the value, adding it to ids if needed.
+func intern(ids map[string]int32, value string) int32 {
+	if value == wildcard {
+		return wildcardID
+	}
+	id, ok := ids[value]
+	if !ok {
+		id = int32(len(ids) + 1)
+		ids[value] = id
+	}
+	return id
+}
+
+// compile returns the interned form of the platform, without allocating for
+// platforms in canonical form. Values unknown to the compiled entries have
+// the ID 0, which only matches wildcards.
+func (c *compiledComparer) compile(platform specs.Platform) compiledPlatform {
+	arch, variant := normalizeArch(platform.Architecture, platform.Variant)
+	p := compiledPlatform{
+		os:        c.os[normalizeOS(platform.OS)],
+		arch:      c.arch[arch],
+		variant:   c.variant[variant],
+		nfeatures: len(platform.OSFeatures),
+	}
+	strippedWin32k := false
+	for _, f := range platform.OSFeatures {
+		bit, ok := c.features[f]
+		if !ok {
+			bit = unknownFeature
+		}
+		p.features |= bit
+		if f == "win32k" && !strippedWin32k {
+			strippedWin32k = true
+			continue
+		}
+		p.windowsFeatures |= bit
+	}
+	if c.windows {
+		p.osVersion = platform.OSVersion
+	}
+	return p
+}
+
+// match returns true if the entry matches the platform, with the provided
+// features.
+func (e *compiledEntry) match(p *compiledPlatform, features uint64) bool {
+	if (e.os != wildcardID && e.os != p.os) ||
+		(e.arch != wildcardID && e.arch != p.arch) ||
+		(e.variant != wildcardID && e.variant != p.variant) {
+		return false
+	}
+	if e.osvM != nil && !e.osvM.Match(p.osVersion) {
+		return false
+	}
+	return features&^e.features == 0
+}
+
+// index returns the index of the first entry matching the platform, or the
+// number of entries if none does.
+func (c *compiledComparer) index(p *compiledPlatform, stripped bool) int {
+	for i := range c.entries {
+		e := &c.entries[i]
+		features := p.features
+		if e.stripWin32k {
+			features = p.windowsFeatures
+		}
+		if stripped {
+			features = 0
+		}
+		if e.match(p, features) {
+			return i
+		}
+	}
+	return len(c.entries)
+}
+
+func (c *compiledComparer) Match(platform specs.Platform) bool {
+	p := c.compile(platform)
+	return c.index(&p, false) < len(c.entries)
+}
+
+func (c *compiledComparer) rank(platform specs.Platform) compiledRank {
+	p := c.compile(platform)
+
+	c.mu.Lock()
+	r, ok := c.ranks[p]
+	c.mu.Unlock()
+	if ok {
+		return r
+	}
+
+	r = compiledRank{
+		index:         c.index(&p, false),
+		strippedIndex: c.index(&p, true),
+		nfeatures:     p.nfeatures,
+	}
+
+	c.mu.Lock()
+	if len(c.ranks) >= maxCompiledRanks {
+		clear(c.ranks)
+	}
+	c.ranks[p] = r
+	c.mu.Unlock()
+	return r
+}
+
+// Rank ranks the platforms as [Ordered].
+func (c *compiledComparer) Rank(platform specs.Platform) (int, bool) {
+	r := c.rank(platform)
+	if r.index == len(c.entries) {
+		return 0, false
+	}
+	return r.value(), true
+}
+
+// Less orders platforms as [Ordered]: by the first entry matching them,
+// preferring the platform with the most features when both match the same
+// entry. When neither matches, they are ordered without their features.
+func (c *compiledComparer) Less(p1, p2 specs.Platform) bool {
+	r1, r2 := c.rank(p1), c.rank(p2)
+	ok1, ok2 := r1.index < len(c.entries), r2.index < len(c.entries)
+	if !ok1 && !ok2 && (r1.nfeatures > 0 || r2.nfeatures > 0) {
+		return r1.strippedIndex < r2.strippedIndex
+	}
+	return lessRank(r1.value(), ok1, r2.value(), ok2)
+}
+
+// value returns the rank of a matched platform, as returned by
+// [orderedPlatformComparer.Rank].
+func (r compiledRank) value() int {
+	return r.index*featureRanks + featureRanks - 1 - min(r.nfeatures, featureRanks-1)
+}
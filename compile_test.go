@@ -0,0 +1,178 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"fmt"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var compileTestPlatforms = []specs.Platform{
+	{OS: "linux", Architecture: "amd64"},
+	{OS: "linux", Architecture: "amd64", Variant: "v2"},
+	{OS: "linux", Architecture: "amd64", Variant: "v3"},
+	{OS: "linux", Architecture: "x86_64", Variant: "v4"},
+	{OS: "linux", Architecture: "386"},
+	{OS: "linux", Architecture: "arm64"},
+	{OS: "linux", Architecture: "aarch64", Variant: "v8"},
+	{OS: "linux", Architecture: "arm64", Variant: "v8.1"},
+	{OS: "linux", Architecture: "arm64", Variant: "v8.2"},
+	{OS: "linux", Architecture: "arm64", Variant: "v9"},
+	{OS: "linux", Architecture: "arm", Variant: "v5"},
+	{OS: "linux", Architecture: "arm", Variant: "6"},
+	{OS: "linux", Architecture: "arm"},
+	{OS: "linux", Architecture: "arm", Variant: "v8"},
+	{OS: "Linux", Architecture: "ppc64le"},
+	{OS: "linux", Architecture: "s390x", Variant: "z15"},
+	{OS: "linux", Architecture: "riscv64"},
+	{OS: "linux", Architecture: "amd64", OSFeatures: []string{"musl"}},
+	{OS: "linux", Architecture: "amd64", OSFeatures: []string{"musl", "fips"}},
+	{OS: "linux", Architecture: "amd64", OSFeatures: []string{"unknown"}},
+	{OS: "linux", Architecture: "arm64", OSFeatures: []string{"musl", "musl"}},
+	{OS: "linux", Architecture: "xtensa"},
+	{OS: "freebsd", Architecture: "amd64"},
+	{OS: "darwin", Architecture: "arm64"},
+	{OS: "windows", Architecture: "amd64"},
+	{OS: "windows", Architecture: "amd64", OSVersion: "10.0.17763.5830"},
+	{OS: "windows", Architecture: "amd64", OSVersion: "10.0.20348.2527"},
+	{OS: "windows", Architecture: "amd64", OSVersion: "10.0.26100.1742"},
+	{OS: "windows", Architecture: "amd64", OSVersion: "10.0.20348.2527", OSFeatures: []string{"win32k"}},
+	{OS: "windows", Architecture: "amd64", OSVersion: "10.0.20348.2527", OSFeatures: []string{"win32k", "win32k"}},
+	{OS: "windows", Architecture: "arm64", OSVersion: "10.0.20348.2527"},
+}
+
+func TestCompile(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		m    MatchComparer
+	}{
+		{"only linux/amd64/v3", Only(MustParse("linux/amd64/v3"))},
+		{"only linux/arm64/v8.2", Only(MustParse("linux/arm64/v8.2"))},
+		{"only linux/arm/v7", Only(MustParse("linux/arm/v7"))},
+		{"only linux/s390x/z16", Only(MustParse("linux/s390x/z16"))},
+		{"only windows", Only(MustParse("windows(10.0.20348)/amd64"))},
+		{"strict linux/amd64", OnlyStrict(MustParse("linux/amd64"))},
+		{"ordered features", Ordered(MustParse("linux(+musl+fips)/amd64"), MustParse("linux(+musl)/arm64"), MustParse("linux/386"))},
+		{"ordered wildcards", Ordered(MustParse("linux/*"), MustParse("*/arm64"), MustParse("windows/*/*"))},
+		{"ordered windows features", Ordered(MustParse("windows(10.0.26100+win32k)/amd64"), MustParse("windows/arm64"))},
+		{"default", Default()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			compiled := Compile(tc.m)
+			if _, ok := compiled.(*compiledComparer); !ok && tc.name != "default" {
+				t.Fatalf("expected %T to be compiled", tc.m)
+			}
+			for _, p := range compileTestPlatforms {
+				if expected, actual := tc.m.Match(p), compiled.Match(p); expected != actual {
+					t.Errorf("Match(%q) = %t, expected %t", FormatAll(p), actual, expected)
+				}
+			}
+			// Less is checked twice, the second time using memoized ranks.
+			for range 2 {
+				for _, p1 := range compileTestPlatforms {
+					for _, p2 := range compileTestPlatforms {
+						if expected, actual := tc.m.Less(p1, p2), compiled.Less(p1, p2); expected != actual {
+							t.Errorf("Less(%q, %q) = %t, expected %t", FormatAll(p1), FormatAll(p2), actual, expected)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCompileUnsupported(t *testing.T) {
+	for _, m := range []MatchComparer{
+		All,
+		Any(MustParse("linux/amd64")),
+		OnlyOS(MustParse("linux/amd64")),
+		orderedPlatformComparer{matchers: []Matcher{NewMatcher(MustParse("linux/amd64")), All}},
+	} {
+		if compiled, ok := Compile(m).(*compiledComparer); ok {
+			t.Errorf("expected %T not to be compiled, got %v", m, compiled)
+		}
+	}
+}
+
+func TestCompileMatchAllocs(t *testing.T) {
+	m := Compile(Only(MustParse("windows(10.0.20348)/amd64")))
+	platforms := make([]specs.Platform, len(compileTestPlatforms))
+	for i, p := range compileTestPlatforms {
+		platforms[i] = Normalize(p)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, p := range platforms {
+			m.Match(p)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected Match not to allocate, got %v allocations", allocs)
+	}
+}
+
+func benchmarkIndex(n int) []specs.Descriptor {
+	descs := make([]specs.Descriptor, n)
+	for i := range descs {
+		p := Normalize(compileTestPlatforms[i%len(compileTestPlatforms)])
+		descs[i] = specs.Descriptor{Size: int64(i), Platform: &p}
+	}
+	return descs
+}
+
+func BenchmarkCompiledMatch(b *testing.B) {
+	descs := benchmarkIndex(200)
+	for _, bc := range []struct {
+		name string
+		m    MatchComparer
+	}{
+		{"only", Only(MustParse("linux/arm64/v8.2"))},
+		{"compiled", Compile(Only(MustParse("linux/arm64/v8.2")))},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				for _, desc := range descs {
+					bc.m.Match(*desc.Platform)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCompiledSort(b *testing.B) {
+	for _, n := range []int{10, 200} {
+		for _, bc := range []struct {
+			name string
+			m    MatchComparer
+		}{
+			{"only", Only(MustParse("linux/arm64/v8.2"))},
+			{"compiled", Compile(Only(MustParse("linux/arm64/v8.2")))},
+		} {
+			b.Run(fmt.Sprintf("%s/%d", bc.name, n), func(b *testing.B) {
+				descs := benchmarkIndex(n)
+				sorted := make([]specs.Descriptor, n)
+				b.ReportAllocs()
+				for b.Loop() {
+					copy(sorted, descs)
+					Sort(bc.m, sorted)
+				}
+			})
+		}
+	}
+}
@@ -17,13 +17,15 @@
 package platforms
 
 import (
+	"fmt"
 	"runtime"
 	"sync"
 
 	"github.com/containerd/log"
 )
 
-// Present the ARM instruction set architecture, eg: v7, v8
+// Present the instruction set architecture variant, eg: v7, v8 for ARM or
+// v2, v3 for amd64
 // Don't use this value directly; call cpuVariant() instead.
 var cpuVariantValue string
 
@@ -31,9 +33,9 @@ var cpuVariantOnce sync.Once
 
 func cpuVariant() string {
 	cpuVariantOnce.Do(func() {
-		if isArmArch(runtime.GOARCH) {
+		if hasCPUVariant(runtime.GOARCH) {
 			var err error
-			cpuVariantValue, err = getCPUVariant()
+			cpuVariantValue, err = getCPUVariant(hostProbe{})
 			if err != nil {
 				log.L.Errorf("Error getCPUVariant for OS %s: %v", runtime.GOOS, err)
 			}
@@ -41,3 +43,44 @@ func cpuVariant() string {
 	})
 	return cpuVariantValue
 }
+
+// getCPUVariant returns the cpu variant of the host described by the probe.
+func getCPUVariant(probe HostProbe) (string, error) {
+	goos, goarch := probe.Runtime()
+	if goos == "linux" {
+		return getLinuxCPUVariant(probe)
+	}
+
+	if !isArmArch(goarch) {
+		// Variants of other architectures are only detected on Linux
+		return "", nil
+	}
+
+	var variant string
+	switch goos {
+	case "windows", "darwin":
+		// Windows/Darwin only supports v7 for ARM32 and v8 for ARM64 and so we can use
+		// the GOARCH to determine the variants
+		switch goarch {
+		case "arm64":
+			variant = "v8"
+		case "arm":
+			variant = "v7"
+		default:
+			variant = "unknown"
+		}
+	case "freebsd":
+		// FreeBSD supports ARMv6 and ARMv7 as well as ARMv4 and ARMv5 (though deprecated)
+		// detecting those variants is currently unimplemented
+		switch goarch {
+		case "arm64":
+			variant = "v8"
+		default:
+			variant = "unknown"
+		}
+	default:
+		return "", fmt.Errorf("getCPUVariant for OS %s: %v", goos, errNotImplemented)
+	}
+
+	return variant, nil
+}
@@ -23,33 +23,8 @@ import (
 	"runtime"
 )
 
-func getCPUVariant() (string, error) {
-	var variant string
-
-	switch runtime.GOOS {
-	case "windows", "darwin":
-		// Windows/Darwin only supports v7 for ARM32 and v8 for ARM64 and so we can use
-		// runtime.GOARCH to determine the variants
-		switch runtime.GOARCH {
-		case "arm64":
-			variant = "v8"
-		case "arm":
-			variant = "v7"
-		default:
-			variant = "unknown"
-		}
-	case "freebsd":
-		// FreeBSD supports ARMv6 and ARMv7 as well as ARMv4 and ARMv5 (though deprecated)
-		// detecting those variants is currently unimplemented
-		switch runtime.GOARCH {
-		case "arm64":
-			variant = "v8"
-		default:
-			variant = "unknown"
-		}
-	default:
-		return "", fmt.Errorf("getCPUVariant for OS %s: %v", runtime.GOOS, errNotImplemented)
-	}
-
-	return variant, nil
+// getMachineArch retrieves the machine architecture, which is only
+// implemented on Linux.
+func getMachineArch() (string, error) {
+	return "", fmt.Errorf("getMachineArch for OS %s: %w", runtime.GOOS, errNotImplemented)
 }
@@ -0,0 +1,475 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// For Linux, the kernel has already detected the ABI, ISA and Features.
+// So we don't need to access the ARM registers to detect platform information
+// by ourselves. We can just parse these information from /proc/cpuinfo
+func getCPUInfo(probe HostProbe, pattern string) (info string, err error) {
+	cpuinfo, err := probe.CPUInfo()
+	if err != nil {
+		return "", err
+	}
+	defer cpuinfo.Close()
+
+	// Start to Parse the Cpuinfo line by line. For SMP SoC, we parse
+	// the first core is enough.
+	scanner := bufio.NewScanner(cpuinfo)
+	for scanner.Scan() {
+		newline := scanner.Text()
+		list := strings.Split(newline, ":")
+
+		if len(list) > 1 && strings.EqualFold(strings.TrimSpace(list[0]), pattern) {
+			return strings.TrimSpace(list[1]), nil
+		}
+	}
+
+	// Check whether the scanner encountered errors
+	err = scanner.Err()
+	if err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("getCPUInfo for pattern %s: %w", pattern, errNotFound)
+}
+
+// getCPUVariantFromArch get CPU variant from arch through a system call
+func getCPUVariantFromArch(arch string) (string, error) {
+	var variant string
+
+	arch = strings.ToLower(arch)
+
+	if arch == "aarch64" {
+		variant = "8"
+	} else if len(arch) >= 5 && arch[0:4] == "armv" {
+		// Valid arch format is in form of armvXx
+		switch arch[3:5] {
+		case "v8":
+			variant = "8"
+		case "v7":
+			variant = "7"
+		case "v6":
+			variant = "6"
+		case "v5":
+			variant = "5"
+		case "v4":
+			variant = "4"
+		case "v3":
+			variant = "3"
+		default:
+			variant = "unknown"
+		}
+	} else {
+		return "", fmt.Errorf("getCPUVariantFromArch invalid arch: %s, %w", arch, errInvalidArgument)
+	}
+	return variant, nil
+}
+
+// getLinuxCPUVariant returns the cpu variant for the architecture of a Linux
+// host.
+func getLinuxCPUVariant(probe HostProbe) (string, error) {
+	_, arch := probe.Runtime()
+	switch arch {
+	case "amd64":
+		return getAMD64Variant(probe)
+	case "ppc64le":
+		return getPPC64LEVariant(probe)
+	case "riscv64":
+		return getRISCV64Variant(probe)
+	case "s390x":
+		return getS390XVariant(probe)
+	}
+	return getARMVariant(probe)
+}
+
+// getAMD64Variant returns the x86-64 microarchitecture level from the "flags"
+// field of /proc/cpuinfo. The baseline level v1 is returned as an empty
+// variant, matching the normalized form.
+func getAMD64Variant(probe HostProbe) (string, error) {
+	flags, err := getCPUInfo(probe, "flags")
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failure getting CPU flags: %v", err)
+	}
+
+	if level := getAMD64LevelFromFlags(flags); level > 1 {
+		return "v" + strconv.Itoa(level), nil
+	}
+	return "", nil
+}
+
+// amd64LevelFlags lists the /proc/cpuinfo flags required by each x86-64
+// microarchitecture level above the baseline, as defined by the x86-64 psABI.
+var amd64LevelFlags = [][]string{
+	// v2
+	{"cx16", "lahf_lm", "popcnt", "pni", "sse4_1", "sse4_2", "ssse3"},
+	// v3
+	{"abm", "avx", "avx2", "bmi1", "bmi2", "f16c", "fma", "movbe", "xsave"},
+	// v4
+	{"avx512f", "avx512bw", "avx512cd", "avx512dq", "avx512vl"},
+}
+
+// getAMD64LevelFromFlags returns the highest x86-64 microarchitecture level
+// supported by the space separated cpu flags.
+func getAMD64LevelFromFlags(flags string) int {
+	supported := make(map[string]bool)
+	for _, flag := range strings.Fields(flags) {
+		supported[flag] = true
+	}
+
+	level := 1
+	for _, required := range amd64LevelFlags {
+		for _, flag := range required {
+			if !supported[flag] {
+				return level
+			}
+		}
+		level++
+	}
+	return level
+}
+
+// getPPC64LEVariant returns the POWER ISA level from the "cpu" field of
+// /proc/cpuinfo. The baseline level power8 is returned as an empty variant,
+// matching the normalized form.
+func getPPC64LEVariant(probe HostProbe) (string, error) {
+	cpu, err := getCPUInfo(probe, "cpu")
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failure getting CPU model: %v", err)
+	}
+	return getPPC64LEVariantFromCPU(cpu), nil
+}
+
+// getPPC64LEVariantFromCPU returns the POWER ISA level of a cpu model, such as
+// "POWER9 (architected), altivec supported".
+func getPPC64LEVariantFromCPU(cpu string) string {
+	model, ok := strings.CutPrefix(strings.ToLower(cpu), "power")
+	if !ok {
+		return ""
+	}
+	end := strings.IndexFunc(model, func(r rune) bool { return r < '0' || r > '9' })
+	if end >= 0 {
+		model = model[:end]
+	}
+	level, err := strconv.Atoi(model)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case level >= 10:
+		return "power10"
+	case level == 9:
+		return "power9"
+	}
+	return ""
+}
+
+// getS390XVariant returns the IBM Z machine level from the "machine =" entry
+// of the first processor in /proc/cpuinfo, falling back to the "facilities"
+// field. The baseline level z13 is returned as an empty variant, matching the
+// normalized form.
+func getS390XVariant(probe HostProbe) (string, error) {
+	processor, err := getCPUInfo(probe, "processor 0")
+	if err != nil && !errors.Is(err, errNotFound) {
+		return "", fmt.Errorf("failure getting CPU machine: %v", err)
+	}
+	facilities, err := getCPUInfo(probe, "facilities")
+	if err != nil && !errors.Is(err, errNotFound) {
+		return "", fmt.Errorf("failure getting CPU facilities: %v", err)
+	}
+
+	var machine string
+	for field := range strings.SplitSeq(processor, ",") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(field), "machine ="); ok {
+			machine = strings.TrimSpace(value)
+		}
+	}
+	return getS390XVariantFromCPUInfo(machine, facilities), nil
+}
+
+// s390xMachineLevels maps IBM Z machine types to their machine level.
+var s390xMachineLevels = map[string]string{
+	"2964": "", // z13
+	"2965": "", // z13s
+	"3906": "z14",
+	"3907": "z14", // z14 ZR1
+	"8561": "z15",
+	"8562": "z15", // z15 T02
+	"3931": "z16",
+	"3932": "z16", // z16 A02
+	"9175": "z16", // z17, runs z16 binaries
+	"9176": "z16",
+}
+
+// s390xLevelFacilities lists the facility bits introduced by each IBM Z
+// machine level above z13.
+var s390xLevelFacilities = []struct {
+	level      string
+	facilities []string
+}{
+	{
+		// vector-enhancements facility 1
+		level:      "z14",
+		facilities: []string{"135"},
+	},
+	{
+		// miscellaneous-instruction-extensions facility 3,
+		// vector-enhancements facility 2
+		level:      "z15",
+		facilities: []string{"61", "148"},
+	},
+	{
+		// neural-network-processing-assist facility,
+		// vector-packed-decimal-enhancement facility 2
+		level:      "z16",
+		facilities: []string{"165", "192"},
+	},
+}
+
+// getS390XVariantFromCPUInfo returns the IBM Z machine level from the machine
+// type, or from the space separated facility bits if the machine type is not
+// known.
+func getS390XVariantFromCPUInfo(machine, facilities string) string {
+	if level, ok := s390xMachineLevels[machine]; ok {
+		return level
+	}
+
+	supported := make(map[string]bool)
+	for _, facility := range strings.Fields(facilities) {
+		supported[facility] = true
+	}
+
+	var variant string
+	for _, level := range s390xLevelFacilities {
+		for _, facility := range level.facilities {
+			if !supported[facility] {
+				return variant
+			}
+		}
+		variant = level.level
+	}
+	return variant
+}
+
+// getRISCV64Variant returns the RISC-V application profile from the "isa"
+// field of /proc/cpuinfo. The baseline profile rva20u64 is returned as an
+// empty variant, matching the normalized form.
+func getRISCV64Variant(probe HostProbe) (string, error) {
+	isa, err := getCPUInfo(probe, "isa")
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failure getting CPU isa: %v", err)
+	}
+	return getRISCV64VariantFromISA(isa), nil
+}
+
+// riscv64ProfileExtensions lists the extensions reported in the isa string
+// which are used to identify each RISC-V application profile above rva20u64.
+// Extensions mandated by a profile which the kernel does not report are
+// omitted.
+var riscv64ProfileExtensions = []struct {
+	profile    string
+	extensions []string
+}{
+	{
+		profile:    "rva22u64",
+		extensions: []string{"zba", "zbb", "zbs", "zicbom", "zicboz", "zihintpause", "zfhmin", "zkt"},
+	},
+	{
+		profile:    "rva23u64",
+		extensions: []string{"v", "zvfhmin", "zvbb", "zvkt", "zihintntl", "zicond", "zimop", "zcmop", "zcb", "zfa", "zawrs"},
+	},
+}
+
+// getRISCV64VariantFromISA returns the highest RISC-V application profile
+// supported by the isa string, such as "rv64imafdcv_zicbom_zicboz".
+func getRISCV64VariantFromISA(isa string) string {
+	isa = strings.ToLower(isa)
+	base, rest, _ := strings.Cut(isa, "_")
+	if !strings.HasPrefix(base, "rv64") {
+		return ""
+	}
+
+	// Single letter extensions follow the base, multi-letter extensions
+	// are separated by underscores.
+	supported := make(map[string]bool)
+	for _, ext := range strings.TrimPrefix(base, "rv64") {
+		supported[string(ext)] = true
+	}
+	for ext := range strings.SplitSeq(rest, "_") {
+		supported[ext] = true
+	}
+	if supported["g"] {
+		// g is shorthand for imafd
+		for _, ext := range []string{"i", "m", "a", "f", "d"} {
+			supported[ext] = true
+		}
+	}
+
+	// rva20u64 is the baseline
+	for _, ext := range []string{"i", "m", "a", "f", "d", "c"} {
+		if !supported[ext] {
+			return ""
+		}
+	}
+
+	var variant string
+	for _, profile := range riscv64ProfileExtensions {
+		for _, ext := range profile.extensions {
+			if !supported[ext] {
+				return variant
+			}
+		}
+		variant = profile.profile
+	}
+	return variant
+}
+
+// getARMVariant returns cpu variant for ARM
+// We first try reading "Cpu architecture" field from /proc/cpuinfo
+// If we can't find it, then fall back using a system call
+// This is to cover running ARM in emulated environment on x86 host as this field in /proc/cpuinfo
+// was not present.
+func getARMVariant(probe HostProbe) (string, error) {
+	variant, err := getCPUInfo(probe, "Cpu architecture")
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			// Let's try getting CPU variant from machine architecture
+			arch, err := probe.MachineArch()
+			if err != nil {
+				return "", fmt.Errorf("failure getting machine architecture: %v", err)
+			}
+
+			variant, err = getCPUVariantFromArch(arch)
+			if err != nil {
+				return "", fmt.Errorf("failure getting CPU variant from machine architecture: %v", err)
+			}
+		} else {
+			return "", fmt.Errorf("failure getting CPU variant: %v", err)
+		}
+	}
+
+	// handle edge case for Raspberry Pi ARMv6 devices (which due to a kernel quirk, report "CPU architecture: 7")
+	// https://www.raspberrypi.org/forums/viewtopic.php?t=12614
+	_, goarch := probe.Runtime()
+	if goarch == "arm" && variant == "7" {
+		model, err := getCPUInfo(probe, "model name")
+		if err == nil && strings.HasPrefix(strings.ToLower(model), "armv6-compatible") {
+			variant = "6"
+		}
+	}
+
+	switch strings.ToLower(variant) {
+	case "8", "aarch64":
+		variant = "v8"
+		if goarch == "arm64" {
+			if features, err := getCPUInfo(probe, "Features"); err == nil {
+				variant = getARM64VariantFromFeatures(features)
+			}
+		}
+	case "7", "7m", "?(12)", "?(13)", "?(14)", "?(15)", "?(16)", "?(17)":
+		variant = "v7"
+	case "6", "6tej":
+		variant = "v6"
+	case "5", "5t", "5te", "5tej":
+		variant = "v5"
+	case "4", "4t":
+		variant = "v4"
+	case "3":
+		variant = "v3"
+	default:
+		variant = "unknown"
+	}
+
+	return variant, nil
+}
+
+// arm64LevelFeatures lists the /proc/cpuinfo features used to identify each
+// ARMv8.x level above v8.0. Only features which are mandatory at that level and
+// reported by the kernel through HWCAP/HWCAP2 are used. Features which are
+// optional before becoming mandatory, such as i8mm and bf16 from v8.2, are
+// not used as they are also found on ARMv9.0 CPUs.
+var arm64LevelFeatures = [][]string{
+	// v8.1
+	{"atomics", "asimdrdm", "crc32"},
+	// v8.2
+	{"dcpop"},
+	// v8.3
+	{"jscvt", "fcma", "lrcpc", "paca", "pacg"},
+	// v8.4
+	{"dit", "uscat", "ilrcpc", "flagm"},
+	// v8.5
+	{"sb", "dcpodp", "flagm2", "frint"},
+	// v8.6
+	{"ecv"},
+	// v8.7
+	{"wfxt"},
+	// v8.8
+	{"mops", "hbc"},
+	// v8.9
+	{"cssc"},
+}
+
+// getARM64VariantFromFeatures returns the highest ARMv8.x or ARMv9.x variant
+// supported by the space separated cpu features.
+//
+// ARMv9.x includes the features of ARMv8.(x+5) and mandates SVE2, so a CPU
+// supporting SVE2 and ARMv8.5 or later is reported as ARMv9.x up to v9.4, after
+// which the levels can no longer be told apart by their ARMv8.x features.
+func getARM64VariantFromFeatures(features string) string {
+	supported := make(map[string]bool)
+	for _, feature := range strings.Fields(features) {
+		supported[feature] = true
+	}
+
+	minor := 0
+levels:
+	for _, required := range arm64LevelFeatures {
+		for _, feature := range required {
+			if !supported[feature] {
+				break levels
+			}
+		}
+		minor++
+	}
+
+	if supported["sve2"] && minor >= 5 {
+		if minor == 5 {
+			return "v9"
+		}
+		return "v9." + strconv.Itoa(minor-5)
+	}
+	if minor == 0 {
+		return "v8"
+	}
+	return "v8." + strconv.Itoa(minor)
+}
@@ -0,0 +1,402 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGetCPUVariantFromArch(t *testing.T) {
+	for _, testcase := range []struct {
+		name        string
+		input       string
+		output      string
+		expectedErr error
+	}{
+		{
+			name:        "Test aarch64",
+			input:       "aarch64",
+			output:      "8",
+			expectedErr: nil,
+		},
+		{
+			name:        "Test Armv8 with capital",
+			input:       "Armv8",
+			output:      "8",
+			expectedErr: nil,
+		},
+		{
+			name:        "Test armv7",
+			input:       "armv7",
+			output:      "7",
+			expectedErr: nil,
+		},
+		{
+			name:        "Test armv6",
+			input:       "armv6",
+			output:      "6",
+			expectedErr: nil,
+		},
+		{
+			name:        "Test armv5",
+			input:       "armv5",
+			output:      "5",
+			expectedErr: nil,
+		},
+		{
+			name:        "Test armv4",
+			input:       "armv4",
+			output:      "4",
+			expectedErr: nil,
+		},
+		{
+			name:        "Test armv3",
+			input:       "armv3",
+			output:      "3",
+			expectedErr: nil,
+		},
+		{
+			name:        "Test unknown input",
+			input:       "armv9",
+			output:      "unknown",
+			expectedErr: nil,
+		},
+		{
+			name:        "Test invalid input which doesn't start with armv",
+			input:       "armxxxx",
+			output:      "",
+			expectedErr: errInvalidArgument,
+		},
+		{
+			name:        "Test invalid input whose length is less than 5",
+			input:       "armv",
+			output:      "",
+			expectedErr: errInvalidArgument,
+		},
+		{
+			name:        "Test invalid input whose length is less than 4",
+			input:       "arm",
+			output:      "",
+			expectedErr: errInvalidArgument,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			t.Logf("input: %v", testcase.input)
+
+			variant, err := getCPUVariantFromArch(testcase.input)
+
+			if err == nil {
+				if testcase.expectedErr != nil {
+					t.Fatalf("Expect to get error: %v, however no error got", testcase.expectedErr)
+				} else {
+					if variant != testcase.output {
+						t.Fatalf("Expect to get variant: %v, however %v returned", testcase.output, variant)
+					}
+				}
+			} else {
+				if !errors.Is(err, testcase.expectedErr) {
+					t.Fatalf("Expect to get error: %v, however error %v returned", testcase.expectedErr, err)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAMD64LevelFromFlags(t *testing.T) {
+	const (
+		v1 = "fpu vme de pse tsc msr pae mce cx8 apic sep mtrr pge mca cmov pat pse36 clflush mmx fxsr sse sse2 syscall nx lm"
+		v2 = v1 + " pni ssse3 cx16 sse4_1 sse4_2 popcnt lahf_lm"
+		v3 = v2 + " fma movbe xsave avx f16c abm bmi1 avx2 bmi2"
+		v4 = v3 + " avx512f avx512dq avx512cd avx512bw avx512vl"
+	)
+	for _, testcase := range []struct {
+		name  string
+		flags string
+		level int
+	}{
+		{
+			name:  "empty",
+			flags: "",
+			level: 1,
+		},
+		{
+			name:  "baseline",
+			flags: v1,
+			level: 1,
+		},
+		{
+			name:  "v2",
+			flags: v2,
+			level: 2,
+		},
+		{
+			name:  "v2 without popcnt",
+			flags: strings.Replace(v2, " popcnt", "", 1),
+			level: 1,
+		},
+		{
+			name:  "v3",
+			flags: v3,
+			level: 3,
+		},
+		{
+			name:  "v3 without avx2",
+			flags: strings.Replace(v3, " avx2", "", 1),
+			level: 2,
+		},
+		{
+			name:  "v4",
+			flags: v4,
+			level: 4,
+		},
+		{
+			name:  "avx512 without v3",
+			flags: v2 + " avx512f avx512dq avx512cd avx512bw avx512vl",
+			level: 2,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if level := getAMD64LevelFromFlags(testcase.flags); level != testcase.level {
+				t.Fatalf("Expect to get level: %v, however %v returned", testcase.level, level)
+			}
+		})
+	}
+}
+
+func TestGetARM64VariantFromFeatures(t *testing.T) {
+	const (
+		v80 = "fp asimd evtstrm aes pmull sha1 sha2 cpuid"
+		v81 = v80 + " crc32 atomics asimdrdm"
+		v82 = v81 + " fphp asimdhp dcpop"
+		v83 = v82 + " jscvt fcma lrcpc paca pacg"
+		v84 = v83 + " dit uscat ilrcpc flagm"
+		v85 = v84 + " sb dcpodp flagm2 frint"
+		v86 = v85 + " i8mm bf16 ecv"
+		v89 = v86 + " wfxt mops hbc cssc"
+	)
+	for _, testcase := range []struct {
+		name     string
+		features string
+		variant  string
+	}{
+		{
+			name:     "empty",
+			features: "",
+			variant:  "v8",
+		},
+		{
+			name:     "Cortex-A53",
+			features: "fp asimd evtstrm aes pmull sha1 sha2 crc32 cpuid",
+			variant:  "v8",
+		},
+		{
+			name:     "v8.1",
+			features: v81,
+			variant:  "v8.1",
+		},
+		{
+			name:     "Graviton2",
+			features: "fp asimd evtstrm aes pmull sha1 sha2 crc32 atomics fphp asimdhp cpuid asimdrdm lrcpc dcpop asimddp ssbs",
+			variant:  "v8.2",
+		},
+		{
+			name:     "v8.3",
+			features: v83,
+			variant:  "v8.3",
+		},
+		{
+			name:     "Graviton3",
+			features: "fp asimd evtstrm aes pmull sha1 sha2 crc32 atomics fphp asimdhp cpuid asimdrdm jscvt fcma lrcpc dcpop sha3 sm3 sm4 asimddp sha512 sve asimdfhm dit uscat ilrcpc flagm ssbs paca pacg dcpodp svei8mm svebf16 i8mm bf16 dgh rng",
+			variant:  "v8.4",
+		},
+		{
+			name:     "v8.5 without sve2",
+			features: v85,
+			variant:  "v8.5",
+		},
+		{
+			name:     "v8.9",
+			features: v89,
+			variant:  "v8.9",
+		},
+		{
+			name:     "v8.4 with sve2",
+			features: v84 + " sve2",
+			variant:  "v8.4",
+		},
+		{
+			name:     "v9.0",
+			features: v85 + " sve sve2",
+			variant:  "v9",
+		},
+		{
+			name:     "Graviton4",
+			features: "fp asimd evtstrm aes pmull sha1 sha2 crc32 atomics fphp asimdhp cpuid asimdrdm jscvt fcma lrcpc dcpop sha3 sm3 sm4 asimddp sha512 sve asimdfhm dit uscat ilrcpc flagm ssbs sb paca pacg dcpodp sve2 sveaes svepmull svebitperm svesha3 svesm4 flagm2 frint svei8mm svebf16 i8mm bf16 dgh rng bti",
+			variant:  "v9",
+		},
+		{
+			name:     "v8.5 with i8mm and bf16",
+			features: v85 + " i8mm bf16",
+			variant:  "v8.5",
+		},
+		{
+			name:     "v9.1",
+			features: v86 + " sve sve2",
+			variant:  "v9.1",
+		},
+		{
+			name:     "v9.4",
+			features: v89 + " sve sve2",
+			variant:  "v9.4",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			variant := getARM64VariantFromFeatures(testcase.features)
+			if variant != testcase.variant {
+				t.Fatalf("Expect to get variant: %v, however %v returned", testcase.variant, variant)
+			}
+			if _, ok := arm64variantToVersion[variant]; !ok {
+				t.Fatalf("variant %v is not a known arm64 variant", variant)
+			}
+		})
+	}
+}
+
+func TestGetRISCV64VariantFromISA(t *testing.T) {
+	const (
+		rva22 = "rv64imafdc_zicbom_zicboz_zicntr_zicsr_zifencei_zihintpause_zihpm_zfhmin_zba_zbb_zbs_zkt"
+		rva23 = "rv64imafdcvh_zicbom_zicboz_zicntr_zicond_zicsr_zifencei_zihintntl_zihintpause_zihpm_zimop_zawrs_zfa_zfhmin_zca_zcb_zcd_zcmop_zba_zbb_zbs_zkt_zvbb_zvfhmin_zvkt"
+	)
+	for _, testcase := range []struct {
+		name    string
+		isa     string
+		variant string
+	}{
+		{
+			name:    "empty",
+			isa:     "",
+			variant: "",
+		},
+		{
+			name:    "rv32",
+			isa:     "rv32imafdc",
+			variant: "",
+		},
+		{
+			name:    "rva20",
+			isa:     "rv64imafdc_zicntr_zicsr_zifencei_zihpm",
+			variant: "",
+		},
+		{
+			name:    "rva20 shorthand",
+			isa:     "rv64gc",
+			variant: "",
+		},
+		{
+			name:    "rva22",
+			isa:     rva22,
+			variant: "rva22u64",
+		},
+		{
+			name:    "rva22 shorthand",
+			isa:     "rv64gc_zicbom_zicboz_zihintpause_zfhmin_zba_zbb_zbs_zkt",
+			variant: "rva22u64",
+		},
+		{
+			name:    "rva22 without zba",
+			isa:     strings.Replace(rva22, "_zba", "", 1),
+			variant: "",
+		},
+		{
+			name:    "rva23",
+			isa:     rva23,
+			variant: "rva23u64",
+		},
+		{
+			name:    "rva23 without vector",
+			isa:     strings.Replace(rva23, "dcvh", "dch", 1),
+			variant: "rva22u64",
+		},
+		{
+			name:    "uppercase",
+			isa:     strings.ToUpper(rva22),
+			variant: "rva22u64",
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if variant := getRISCV64VariantFromISA(testcase.isa); variant != testcase.variant {
+				t.Fatalf("Expect to get variant: %v, however %v returned", testcase.variant, variant)
+			}
+		})
+	}
+}
+
+func TestGetPPC64LEVariantFromCPU(t *testing.T) {
+	for _, testcase := range []struct {
+		cpu     string
+		variant string
+	}{
+		{cpu: "POWER8E (raw), altivec supported", variant: ""},
+		{cpu: "POWER8NVL (raw), altivec supported", variant: ""},
+		{cpu: "POWER9 (architected), altivec supported", variant: "power9"},
+		{cpu: "POWER9, altivec supported", variant: "power9"},
+		{cpu: "POWER10 (architected), altivec supported", variant: "power10"},
+		{cpu: "POWER11 (architected), altivec supported", variant: "power10"},
+		{cpu: "PPC970MP, altivec supported", variant: ""},
+		{cpu: "", variant: ""},
+	} {
+		t.Run(testcase.cpu, func(t *testing.T) {
+			if variant := getPPC64LEVariantFromCPU(testcase.cpu); variant != testcase.variant {
+				t.Fatalf("Expect to get variant: %v, however %v returned", testcase.variant, variant)
+			}
+		})
+	}
+}
+
+func TestGetS390XVariantFromCPUInfo(t *testing.T) {
+	const (
+		z13 = "0 1 2 3 4 6 7 8 9 10 12 14 15 16 17 18 19 20 21 22 23 24 25 26 27 28 30 31 32 33 34 35 36 37 38 40 41 42 43 44 45 47 48 49 50 51 52 53 55 57 73 74 75 76 77 128 129 131"
+		z14 = z13 + " 58 133 134 135 146 147"
+		z15 = z14 + " 61 148 151 152 155"
+		z16 = z15 + " 165 192 193 194 196 197"
+	)
+	for _, testcase := range []struct {
+		name       string
+		machine    string
+		facilities string
+		variant    string
+	}{
+		{name: "z13 machine", machine: "2964", variant: ""},
+		{name: "z14 machine", machine: "3906", variant: "z14"},
+		{name: "z15 machine", machine: "8561", variant: "z15"},
+		{name: "z16 machine", machine: "3931", variant: "z16"},
+		{name: "machine overrides facilities", machine: "3906", facilities: z16, variant: "z14"},
+		{name: "z13 facilities", machine: "1234", facilities: z13, variant: ""},
+		{name: "z14 facilities", facilities: z14, variant: "z14"},
+		{name: "z15 facilities", facilities: z15, variant: "z15"},
+		{name: "z16 facilities", facilities: z16, variant: "z16"},
+		{name: "unknown", variant: ""},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if variant := getS390XVariantFromCPUInfo(testcase.machine, testcase.facilities); variant != testcase.variant {
+				t.Fatalf("Expect to get variant: %v, however %v returned", testcase.variant, variant)
+			}
+		})
+	}
+}
@@ -48,6 +48,18 @@ func isArmArch(arch string) bool {
 	return false
 }
 
+// hasCPUVariant returns true if the CPU variant of the architecture is
+// detected for the default platform.
+//
+// The arch value should be normalized before being passed to this function.
+func hasCPUVariant(arch string) bool {
+	switch arch {
+	case "arm", "arm64", "amd64", "ppc64le", "riscv64", "s390x":
+		return true
+	}
+	return false
+}
+
 // isKnownArch returns true if we know about the architecture.
 //
 // The arch value should be normalized before being passed to this function.
@@ -92,6 +104,28 @@ func normalizeArch(arch, variant string) (string, string) {
 		case "9", "9.0", "v9.0":
 			variant = "v9"
 		}
+	case "ppc64le", "ppc64el", "powerpc64le":
+		arch = "ppc64le"
+		if variant == "power8" {
+			variant = ""
+		}
+	case "ppc64":
+		if variant == "power8" {
+			variant = ""
+		}
+	case "s390x":
+		if variant == "z13" {
+			variant = ""
+		}
+	case "riscv64":
+		switch variant {
+		case "rva20", "rva20u64":
+			variant = ""
+		case "rva22":
+			variant = "rva22u64"
+		case "rva23":
+			variant = "rva23u64"
+		}
 	case "armhf":
 		arch = "arm"
 		variant = "v7"
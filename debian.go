@@ -0,0 +1,83 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"fmt"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// debianArchs maps normalized Linux architectures to their Debian (dpkg)
+// architecture name. The arm architecture is either armhf or armel, depending
+// on its variant.
+var debianArchs = map[string]string{
+	"386":      "i386",
+	"amd64":    "amd64",
+	"arm64":    "arm64",
+	"loong64":  "loong64",
+	"mips64le": "mips64el",
+	"mipsle":   "mipsel",
+	"ppc64":    "ppc64",
+	"ppc64le":  "ppc64el",
+	"riscv64":  "riscv64",
+	"s390x":    "s390x",
+}
+
+// ToDebianArch returns the Debian (dpkg) architecture name of the normalized
+// platform, such as "arm64" for "linux/arm64" or "armhf" for "linux/arm/v7".
+//
+// The arm/v5 and arm/v6 variants are armel, arm/v7 and arm/v8 are armhf. An
+// error is returned for platforms other than Linux and for architectures
+// Debian does not have.
+func ToDebianArch(platform specs.Platform) (string, error) {
+	p := Normalize(platform)
+	if p.OS != "linux" {
+		return "", fmt.Errorf("%q: %w: no Debian architecture", FormatAll(p), ErrUnknownPlatform)
+	}
+	if p.Architecture == "arm" {
+		switch p.Variant {
+		case "v5", "v6":
+			return "armel", nil
+		case "v7", "v8":
+			return "armhf", nil
+		}
+	}
+	if arch, ok := debianArchs[p.Architecture]; ok {
+		return arch, nil
+	}
+	return "", fmt.Errorf("%q: %w: no Debian architecture", FormatAll(p), ErrUnknownPlatform)
+}
+
+// FromDebianArch returns the normalized Linux platform of a Debian (dpkg)
+// architecture name, such as "linux/ppc64le" for "ppc64el".
+//
+// As for the architecture of a platform specifier, armhf is arm/v7 and armel
+// is arm/v6.
+func FromDebianArch(arch string) (specs.Platform, error) {
+	switch arch {
+	case "armhf", "armel":
+		arch, variant := normalizeArch(arch, "")
+		return specs.Platform{OS: "linux", Architecture: arch, Variant: variant}, nil
+	}
+	for goarch, debarch := range debianArchs {
+		if debarch == arch {
+			return Normalize(specs.Platform{OS: "linux", Architecture: goarch}), nil
+		}
+	}
+	return specs.Platform{}, fmt.Errorf("%q: %w", arch, ErrInvalidArchitecture)
+}
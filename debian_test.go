@@ -0,0 +1,99 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"errors"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestDebianArch(t *testing.T) {
+	for _, tc := range []struct {
+		platform string
+		arch     string
+	}{
+		{"linux/amd64", "amd64"},
+		{"linux/arm64", "arm64"},
+		{"linux/arm/v7", "armhf"},
+		{"linux/arm/v6", "armel"},
+		{"linux/386", "i386"},
+		{"linux/ppc64le", "ppc64el"},
+		{"linux/ppc64", "ppc64"},
+		{"linux/s390x", "s390x"},
+		{"linux/riscv64", "riscv64"},
+		{"linux/mips64le", "mips64el"},
+		{"linux/mipsle", "mipsel"},
+		{"linux/loong64", "loong64"},
+	} {
+		t.Run(tc.platform, func(t *testing.T) {
+			arch, err := ToDebianArch(MustParse(tc.platform))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if arch != tc.arch {
+				t.Errorf("ToDebianArch(%q) = %q, expected %q", tc.platform, arch, tc.arch)
+			}
+
+			p, err := FromDebianArch(tc.arch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(p, MustParse(tc.platform)) {
+				t.Errorf("FromDebianArch(%q) = %q, expected %q", tc.arch, FormatAll(p), tc.platform)
+			}
+		})
+	}
+}
+
+func TestToDebianArch(t *testing.T) {
+	for _, tc := range []struct {
+		platform string
+		arch     string
+	}{
+		{"linux/arm/v5", "armel"},
+		{"linux/arm/v8", "armhf"},
+		{"linux/amd64/v3", "amd64"},
+		{"linux/arm64/v8.2", "arm64"},
+		{"linux/x86_64", "amd64"},
+	} {
+		arch, err := ToDebianArch(MustParse(tc.platform))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if arch != tc.arch {
+			t.Errorf("ToDebianArch(%q) = %q, expected %q", tc.platform, arch, tc.arch)
+		}
+	}
+}
+
+func TestDebianArchErrors(t *testing.T) {
+	for _, p := range []specs.Platform{
+		{OS: "windows", Architecture: "amd64"},
+		{OS: "linux", Architecture: "wasm"},
+	} {
+		if _, err := ToDebianArch(p); !errors.Is(err, ErrUnknownPlatform) {
+			t.Errorf("ToDebianArch(%q): expected %v, got %v", Format(p), ErrUnknownPlatform, err)
+		}
+	}
+	for _, arch := range []string{"", "x86_64", "aarch64", "powerpc"} {
+		if _, err := FromDebianArch(arch); !errors.Is(err, ErrInvalidArchitecture) {
+			t.Errorf("FromDebianArch(%q): expected %v, got %v", arch, ErrInvalidArchitecture, err)
+		}
+	}
+}
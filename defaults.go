@@ -16,6 +16,10 @@
 
 package platforms
 
+import (
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
 // DefaultString returns the default string specifier for the platform,
 // with [PR#6](https://github.com/containerd/platforms/pull/6) the result
 // may now also include the OSVersion from the provided platform specification.
@@ -24,6 +28,42 @@ func DefaultString() string {
 }
 
 // DefaultStrict returns strict form of Default.
+//
+// Only the variant of arm is matched strictly. Other detected variants, such
+// as amd64/v3 or arm64/v8.4, describe what the host can run rather than the
+// platform of most images, so the baseline variant is matched instead.
 func DefaultStrict() MatchComparer {
-	return OnlyStrict(DefaultSpec())
+	return defaultStrictMatchComparer(DefaultSpec())
+}
+
+// defaultStrictMatchComparer returns the strict default matcher for a host
+// with the provided default platform specification.
+func defaultStrictMatchComparer(platform specs.Platform) MatchComparer {
+	platform = Normalize(platform)
+	if platform.Architecture != "arm" {
+		platform.Variant = ""
+	}
+	return OnlyStrict(platform)
+}
+
+// defaultMatchComparer returns the default matcher for a host with the
+// provided default platform specification.
+func defaultMatchComparer(platform specs.Platform) MatchComparer {
+	switch platform.OS {
+	case "darwin":
+		return Ordered(platform, specs.Platform{
+			// darwin runtime also supports Linux binary via runu/LKL
+			OS:           "linux",
+			Architecture: platform.Architecture,
+		})
+	case "freebsd":
+		return Ordered(platform, specs.Platform{
+			OS:           "linux",
+			Architecture: platform.Architecture,
+			Variant:      platform.Variant,
+		})
+	case "windows":
+		return &windowsMatchComparer{Matcher: NewMatcher(platform)}
+	}
+	return Only(platform)
 }
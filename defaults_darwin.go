@@ -36,9 +36,5 @@ func DefaultSpec() specs.Platform {
 
 // Default returns the default matcher for the platform.
 func Default() MatchComparer {
-	return Ordered(DefaultSpec(), specs.Platform{
-		// darwin runtime also supports Linux binary via runu/LKL
-		OS:           "linux",
-		Architecture: runtime.GOARCH,
-	})
+	return defaultMatchComparer(DefaultSpec())
 }
@@ -29,12 +29,12 @@ func DefaultSpec() specs.Platform {
 	return specs.Platform{
 		OS:           runtime.GOOS,
 		Architecture: runtime.GOARCH,
-		// The Variant field will be empty if arch != ARM.
+		// The Variant field will be empty if the CPU variant is not detected.
 		Variant: cpuVariant(),
 	}
 }
 
 // Default returns the default matcher for the platform.
 func Default() MatchComparer {
-	return Only(DefaultSpec())
+	return defaultMatchComparer(DefaultSpec())
 }
@@ -17,20 +17,17 @@
 package platforms
 
 import (
-	"fmt"
 	"runtime"
 
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
-	"golang.org/x/sys/windows"
 )
 
 // DefaultSpec returns the current platform's default platform specification.
 func DefaultSpec() specs.Platform {
-	major, minor, build := windows.RtlGetNtVersionNumbers()
 	return specs.Platform{
 		OS:           runtime.GOOS,
 		Architecture: runtime.GOARCH,
-		OSVersion:    fmt.Sprintf("%d.%d.%d", major, minor, build),
+		OSVersion:    getOSVersion(),
 		// The Variant field will be empty if arch != ARM.
 		Variant: cpuVariant(),
 	}
@@ -38,5 +35,5 @@ func DefaultSpec() specs.Platform {
 
 // Default returns the current platform's default platform specification.
 func Default() MatchComparer {
-	return &windowsMatchComparer{Matcher: NewMatcher(DefaultSpec())}
+	return defaultMatchComparer(DefaultSpec())
 }
@@ -16,7 +16,11 @@
 
 package platforms
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // These errors mirror the errors defined in [github.com/containerd/containerd/errdefs],
 // however, they are not exported as they are not expected to be used as sentinel
@@ -28,3 +32,58 @@ var (
 	errInvalidArgument = errors.New("invalid argument")
 	errNotImplemented  = errors.New("not implemented")
 )
+
+// Errors describing why a platform specifier could not be parsed. They are
+// returned as the Reason of a [ParseError], and may also be wrapped by the
+// functions converting platforms from and to other naming schemes, such as
+// [FromTriple] or [GoEnv]. They may be checked with [errors.Is].
+var (
+	ErrInvalidOS           = errors.New("invalid operating system")
+	ErrInvalidOSVersion    = errors.New("invalid OS version")
+	ErrInvalidOSFeature    = errors.New("invalid OS feature")
+	ErrInvalidArchitecture = errors.New("invalid architecture")
+	ErrInvalidVariant      = errors.New("invalid variant")
+	ErrUnknownPlatform     = errors.New("unknown operating system or architecture")
+	ErrTooManyComponents   = errors.New("too many components")
+)
+
+// ParseError is returned when a platform specifier cannot be parsed.
+type ParseError struct {
+	// Specifier is the platform specifier which could not be parsed.
+	Specifier string
+
+	// Component is the part of the specifier which is invalid.
+	Component string
+
+	// Offset is the byte offset of Component in Specifier.
+	Offset int
+
+	// Reason is one of the sentinel errors, such as [ErrInvalidOS].
+	Reason error
+
+	// Err is the underlying error with further details, if any.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q: %v", e.Specifier, e.Reason)
+	if e.Component != "" && e.Component != e.Specifier {
+		fmt.Fprintf(&b, " %q at offset %d", e.Component, e.Offset)
+	}
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the reason and underlying error. A ParseError is also an
+// invalid argument error.
+func (e *ParseError) Unwrap() []error {
+	errs := []error{e.Reason, errInvalidArgument}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
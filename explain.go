@@ -0,0 +1,178 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// MatchField identifies the platform field which caused a platform not to
+// match. The values are the JSON field names used by the OCI image spec.
+type MatchField string
+
+// Platform fields reported by [MatchResult].
+const (
+	FieldOS           MatchField = "os"
+	FieldArchitecture MatchField = "architecture"
+	FieldVariant      MatchField = "variant"
+	FieldOSVersion    MatchField = "os.version"
+	FieldOSFeatures   MatchField = "os.features"
+)
+
+// MatchResult describes the result of matching a platform.
+//
+// When the platform does not match, Field is the first field which did not
+// match, with the value expected by the matcher and the actual value of the
+// platform. For a Windows OS version, MinOSVersion and MaxOSVersion hold the
+// inclusive range of OS versions the matcher accepts.
+type MatchResult struct {
+	Matched      bool
+	Field        MatchField
+	Expected     string
+	Actual       string
+	MinOSVersion string
+	MaxOSVersion string
+}
+
+// String returns a human readable description of the result.
+func (r MatchResult) String() string {
+	if r.Matched {
+		return "matched"
+	}
+	if r.Field == "" {
+		return "not matched"
+	}
+	if r.MinOSVersion != "" && r.MinOSVersion != r.MaxOSVersion {
+		return fmt.Sprintf("%s is %q but %q only accepts >= %q and <= %q", r.Field, r.Actual, r.Expected, r.MinOSVersion, r.MaxOSVersion)
+	}
+	if r.Field == FieldOSFeatures {
+		return fmt.Sprintf("%s %q are not a subset of %q", r.Field, r.Actual, r.Expected)
+	}
+	return fmt.Sprintf("%s is %q, expected %q", r.Field, r.Actual, r.Expected)
+}
+
+// Explainer is implemented by matchers which can explain why a platform did
+// or did not match.
+type Explainer interface {
+	Explain(platform specs.Platform) MatchResult
+}
+
+// Explain matches the platform using m and returns the result. If m does not
+// implement [Explainer], only Matched is set in the result.
+func Explain(m Matcher, platform specs.Platform) MatchResult {
+	if e, ok := m.(Explainer); ok {
+		return e.Explain(platform)
+	}
+	return MatchResult{Matched: m.Match(platform)}
+}
+
+func mismatch(field MatchField, expected, actual string) MatchResult {
+	return MatchResult{
+		Field:    field,
+		Expected: expected,
+		Actual:   actual,
+	}
+}
+
+// Explain returns why the platform does or does not match.
+func (m *matcher) Explain(platform specs.Platform) MatchResult {
+	normalized := Normalize(platform)
+	switch {
+	case !matchComponent(m.OS, normalized.OS):
+		return mismatch(FieldOS, m.OS, normalized.OS)
+	case !matchComponent(m.Architecture, normalized.Architecture):
+		return mismatch(FieldArchitecture, m.Architecture, normalized.Architecture)
+	case !m.matchVariant(normalized.Variant):
+		return mismatch(FieldVariant, m.Variant, normalized.Variant)
+	case !m.matchOSVersion(platform):
+		r := mismatch(FieldOSVersion, m.OSVersion, platform.OSVersion)
+		if wm, ok := m.osvM.(*windowsVersionMatcher); ok {
+			r.MinOSVersion, r.MaxOSVersion = wm.compatibleRange()
+		}
+		return r
+	case !isOSFeaturesSubset(m.OSFeatures, normalized.OSFeatures):
+		return mismatch(FieldOSFeatures, strings.Join(m.OSFeatures, "+"), strings.Join(normalized.OSFeatures, "+"))
+	}
+	return MatchResult{Matched: true}
+}
+
+// compatibleRange returns the inclusive range of OS versions which match.
+func (m windowsVersionMatcher) compatibleRange() (lowest, highest string) {
+	prefix := strconv.Itoa(int(m.MajorVersion)) + "." + strconv.Itoa(int(m.MinorVersion)) + "."
+	lowestBuild, highestBuild := windowsCompatibleBuilds(m.windowsOSVersion)
+	return prefix + strconv.Itoa(int(lowestBuild)), prefix + strconv.Itoa(int(highestBuild))
+}
+
+func (m windowsStripFeaturesMatcher) Explain(p specs.Platform) MatchResult {
+	return Explain(m.Matcher, stripWin32k(p))
+}
+
+func (c *windowsMatchComparer) Explain(p specs.Platform) MatchResult {
+	return Explain(c.Matcher, p)
+}
+
+// Explain returns why the platform does or does not match. When none of the
+// platforms match, the result closest to matching is returned, preferring
+// earlier platforms.
+func (c orderedPlatformComparer) Explain(platform specs.Platform) MatchResult {
+	return explainClosest(c.matchers, platform)
+}
+
+// Explain returns why the platform does or does not match.
+func (c onlyOSComparer) Explain(platform specs.Platform) MatchResult {
+	normalized := Normalize(platform)
+	switch {
+	case !matchComponent(c.platform.OS, normalized.OS):
+		return mismatch(FieldOS, c.platform.OS, normalized.OS)
+	case c.osvM != nil && !c.osvM.Match(platform.OSVersion):
+		r := mismatch(FieldOSVersion, c.platform.OSVersion, platform.OSVersion)
+		if wm, ok := c.osvM.(*windowsVersionMatcher); ok {
+			r.MinOSVersion, r.MaxOSVersion = wm.compatibleRange()
+		}
+		return r
+	case !isOSFeaturesSubset(c.platform.OSFeatures, normalized.OSFeatures):
+		return mismatch(FieldOSFeatures, strings.Join(c.platform.OSFeatures, "+"), strings.Join(normalized.OSFeatures, "+"))
+	}
+	return MatchResult{Matched: true}
+}
+
+// fieldDepth orders fields from the least to the most specific.
+var fieldDepth = map[MatchField]int{
+	FieldOS:           1,
+	FieldArchitecture: 2,
+	FieldVariant:      3,
+	FieldOSVersion:    4,
+	FieldOSFeatures:   5,
+}
+
+func explainClosest(matchers []Matcher, platform specs.Platform) MatchResult {
+	var closest MatchResult
+	for _, m := range matchers {
+		r := Explain(m, platform)
+		if r.Matched {
+			return r
+		}
+		if fieldDepth[r.Field] > fieldDepth[closest.Field] {
+			closest = r
+		}
+	}
+	return closest
+}
@@ -0,0 +1,157 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestExplain(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		matcher  Matcher
+		platform string
+		expected MatchResult
+		message  string
+	}{
+		{
+			name:     "match",
+			matcher:  NewMatcher(MustParse("linux/amd64")),
+			platform: "linux/x86_64",
+			expected: MatchResult{Matched: true},
+			message:  "matched",
+		},
+		{
+			name:     "os",
+			matcher:  NewMatcher(MustParse("linux/amd64")),
+			platform: "windows/amd64",
+			expected: MatchResult{Field: FieldOS, Expected: "linux", Actual: "windows"},
+			message:  `os is "windows", expected "linux"`,
+		},
+		{
+			name:     "architecture",
+			matcher:  NewMatcher(MustParse("linux/amd64")),
+			platform: "linux/aarch64",
+			expected: MatchResult{Field: FieldArchitecture, Expected: "amd64", Actual: "arm64"},
+			message:  `architecture is "arm64", expected "amd64"`,
+		},
+		{
+			name:     "variant",
+			matcher:  NewMatcher(MustParse("linux/arm/v6")),
+			platform: "linux/arm/v7",
+			expected: MatchResult{Field: FieldVariant, Expected: "v6", Actual: "v7"},
+			message:  `variant is "v7", expected "v6"`,
+		},
+		{
+			name:     "features",
+			matcher:  NewMatcher(MustParse("linux(+gpu)/amd64")),
+			platform: "linux(+gpu+simd)/amd64",
+			expected: MatchResult{Field: FieldOSFeatures, Expected: "gpu", Actual: "gpu+simd"},
+			message:  `os.features "gpu+simd" are not a subset of "gpu"`,
+		},
+		{
+			name:     "windows ltsc",
+			matcher:  NewMatcher(MustParse("windows(10.0.26100)/amd64")),
+			platform: "windows(10.0.17763)/amd64",
+			expected: MatchResult{
+				Field:        FieldOSVersion,
+				Expected:     "10.0.26100",
+				Actual:       "10.0.17763",
+				MinOSVersion: "10.0.20348",
+				MaxOSVersion: "10.0.26100",
+			},
+			message: `os.version is "10.0.17763" but "10.0.26100" only accepts >= "10.0.20348" and <= "10.0.26100"`,
+		},
+		{
+			name:     "windows exact",
+			matcher:  NewMatcher(MustParse("windows(10.0.17763)/amd64")),
+			platform: "windows(10.0.20348)/amd64",
+			expected: MatchResult{
+				Field:        FieldOSVersion,
+				Expected:     "10.0.17763",
+				Actual:       "10.0.20348",
+				MinOSVersion: "10.0.17763",
+				MaxOSVersion: "10.0.17763",
+			},
+			message: `os.version is "10.0.20348", expected "10.0.17763"`,
+		},
+		{
+			name:     "only closest",
+			matcher:  Only(MustParse("windows(10.0.26100)/amd64")),
+			platform: "windows(10.0.17763)/amd64",
+			expected: MatchResult{
+				Field:        FieldOSVersion,
+				Expected:     "10.0.26100",
+				Actual:       "10.0.17763",
+				MinOSVersion: "10.0.20348",
+				MaxOSVersion: "10.0.26100",
+			},
+			message: `os.version is "10.0.17763" but "10.0.26100" only accepts >= "10.0.20348" and <= "10.0.26100"`,
+		},
+		{
+			name:     "only fallback",
+			matcher:  Only(MustParse("linux/amd64")),
+			platform: "linux/386",
+			expected: MatchResult{Matched: true},
+			message:  "matched",
+		},
+		{
+			name:     "ordered first",
+			matcher:  Ordered(MustParse("linux/amd64"), MustParse("linux/arm64")),
+			platform: "linux/riscv64",
+			expected: MatchResult{Field: FieldArchitecture, Expected: "amd64", Actual: "riscv64"},
+			message:  `architecture is "riscv64", expected "amd64"`,
+		},
+		{
+			name:     "only os",
+			matcher:  OnlyOS(MustParse("linux/amd64")),
+			platform: "darwin/amd64",
+			expected: MatchResult{Field: FieldOS, Expected: "linux", Actual: "darwin"},
+			message:  `os is "darwin", expected "linux"`,
+		},
+		{
+			name:     "not an explainer",
+			matcher:  Not(All),
+			platform: "linux/amd64",
+			expected: MatchResult{},
+			message:  "not matched",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Explain(tc.matcher, MustParse(tc.platform))
+			if r != tc.expected {
+				t.Errorf("unexpected result: %#v != %#v", r, tc.expected)
+			}
+			if r.Matched != tc.matcher.Match(MustParse(tc.platform)) {
+				t.Errorf("result %v does not agree with Match", r.Matched)
+			}
+			if msg := r.String(); msg != tc.message {
+				t.Errorf("unexpected message: %q != %q", msg, tc.message)
+			}
+		})
+	}
+}
+
+func TestExplainWindowsStripsWin32k(t *testing.T) {
+	m := NewMatcher(specs.Platform{OS: "windows", Architecture: "amd64"})
+	r := Explain(m, specs.Platform{OS: "windows", Architecture: "amd64", OSFeatures: []string{"win32k"}})
+	if !r.Matched {
+		t.Errorf("expected win32k feature to be ignored: %s", r)
+	}
+}
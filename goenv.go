@@ -0,0 +1,212 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// isGoPlatform returns true if the Go toolchain can target the platform.
+//
+// The list is generated from "go tool dist list". The OS and architecture
+// should be normalized before calling this function.
+func isGoPlatform(os, arch string) bool {
+	switch os + "/" + arch {
+	case "aix/ppc64",
+		"android/386", "android/amd64", "android/arm", "android/arm64",
+		"darwin/amd64", "darwin/arm64",
+		"dragonfly/amd64",
+		"freebsd/386", "freebsd/amd64", "freebsd/arm", "freebsd/arm64",
+		"illumos/amd64",
+		"ios/amd64", "ios/arm64",
+		"js/wasm",
+		"linux/386", "linux/amd64", "linux/arm", "linux/arm64", "linux/loong64", "linux/mips", "linux/mips64", "linux/mips64le", "linux/mipsle", "linux/ppc64", "linux/ppc64le", "linux/riscv64", "linux/s390x",
+		"netbsd/386", "netbsd/amd64", "netbsd/arm", "netbsd/arm64",
+		"openbsd/386", "openbsd/amd64", "openbsd/arm", "openbsd/arm64", "openbsd/ppc64", "openbsd/riscv64",
+		"plan9/386", "plan9/amd64", "plan9/arm",
+		"solaris/amd64",
+		"wasip1/wasm",
+		"windows/386", "windows/amd64", "windows/arm64":
+		return true
+	}
+	return false
+}
+
+// GoEnv returns the Go toolchain environment variables to build for the
+// normalized platform, such as GOOS=linux, GOARCH=arm and GOARM=6 for
+// "linux/arm/v6".
+//
+// The architecture level variable, one of GOARM, GOAMD64, GOARM64, GOPPC64
+// and GORISCV64, is always set, so the environment of the caller does not
+// change the result. As Go does not target arm/v8, it uses GOARM=7 which
+// such CPUs can run. Other variants, such as those of s390x, are not
+// represented and the binaries run on every variant.
+//
+// An error is returned when the Go toolchain cannot target the platform.
+func GoEnv(platform specs.Platform) ([]string, error) {
+	p := Normalize(platform)
+	if !isGoPlatform(p.OS, p.Architecture) {
+		return nil, fmt.Errorf("%q: %w: not supported by the Go toolchain", FormatAll(p), ErrUnknownPlatform)
+	}
+	env := []string{"GOOS=" + p.OS, "GOARCH=" + p.Architecture}
+
+	var key, value string
+	switch p.Architecture {
+	case "arm":
+		key = "GOARM"
+		switch p.Variant {
+		case "v5", "v6", "v7":
+			value = p.Variant[1:]
+		case "v8":
+			value = "7"
+		}
+	case "amd64":
+		key = "GOAMD64"
+		switch p.Variant {
+		case "":
+			value = "v1"
+		case "v2", "v3", "v4":
+			value = p.Variant
+		}
+	case "arm64":
+		key = "GOARM64"
+		switch {
+		case p.Variant == "":
+			value = "v8.0"
+		case p.Variant == "v9":
+			value = "v9.0"
+		case isGoARM64Level(p.Variant):
+			value = p.Variant
+		}
+	case "ppc64", "ppc64le":
+		key = "GOPPC64"
+		switch p.Variant {
+		case "":
+			value = "power8"
+		case "power9", "power10":
+			value = p.Variant
+		}
+	case "riscv64":
+		key = "GORISCV64"
+		switch p.Variant {
+		case "":
+			value = "rva20u64"
+		case "rva22u64", "rva23u64":
+			value = p.Variant
+		}
+	default:
+		return env, nil
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%q: %w %q: not supported by the Go toolchain", FormatAll(p), ErrInvalidVariant, p.Variant)
+	}
+	return append(env, key+"="+value), nil
+}
+
+// FromGoEnv returns the normalized platform targeted by the Go toolchain
+// environment variables, given as "key=value" as with [os.Environ]. When a
+// variable is repeated, the last value is used.
+//
+// As for the go command, GOOS and GOARCH default to the running platform,
+// and the architecture level variables to the lowest level.
+func FromGoEnv(env []string) (specs.Platform, error) {
+	vars := make(map[string]string)
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			vars[k] = v
+		}
+	}
+
+	p := specs.Platform{OS: vars["GOOS"], Architecture: vars["GOARCH"]}
+	if p.OS == "" {
+		p.OS = runtime.GOOS
+	}
+	if p.Architecture == "" {
+		p.Architecture = runtime.GOARCH
+	}
+	if !isGoPlatform(p.OS, p.Architecture) {
+		return specs.Platform{}, fmt.Errorf("%q: %w: not supported by the Go toolchain", p.OS+"/"+p.Architecture, ErrUnknownPlatform)
+	}
+
+	var key, value string
+	switch p.Architecture {
+	case "arm":
+		key = "GOARM"
+		// The floating point mode, as in "6,softfloat", is not represented.
+		value, _, _ = strings.Cut(vars[key], ",")
+		switch value {
+		case "":
+			p.Variant = "v7"
+		case "5", "6", "7":
+			p.Variant = "v" + value
+		}
+	case "amd64":
+		key = "GOAMD64"
+		switch value = vars[key]; value {
+		case "", "v1", "v2", "v3", "v4":
+			p.Variant = value
+		}
+	case "arm64":
+		key = "GOARM64"
+		// The optional features, as in "v8.0,lse", are not represented.
+		value, _, _ = strings.Cut(vars[key], ",")
+		if value == "" || isGoARM64Level(value) {
+			p.Variant = value
+		}
+	case "ppc64", "ppc64le":
+		key = "GOPPC64"
+		switch value = vars[key]; value {
+		case "", "power8", "power9", "power10":
+			p.Variant = value
+		}
+	case "riscv64":
+		key = "GORISCV64"
+		switch value = vars[key]; value {
+		case "", "rva20u64", "rva22u64", "rva23u64":
+			p.Variant = value
+		}
+	}
+	if value != "" && p.Variant == "" {
+		return specs.Platform{}, fmt.Errorf("%s=%q: %w", key, vars[key], ErrInvalidVariant)
+	}
+	return Normalize(p), nil
+}
+
+// isGoARM64Level returns true if the variant is a level accepted by GOARM64,
+// from v8.0 to v8.9 and v9.0 to v9.5.
+func isGoARM64Level(variant string) bool {
+	major, minor, ok := strings.Cut(variant, ".")
+	if !ok || len(minor) != 1 {
+		return false
+	}
+	n, err := strconv.Atoi(minor)
+	if err != nil {
+		return false
+	}
+	switch major {
+	case "v8":
+		return n <= 9
+	case "v9":
+		return n <= 5
+	}
+	return false
+}
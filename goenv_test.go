@@ -0,0 +1,126 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestGoEnv(t *testing.T) {
+	for _, tc := range []struct {
+		platform string
+		env      []string
+		expected string
+	}{
+		{"linux/arm/v6", []string{"GOOS=linux", "GOARCH=arm", "GOARM=6"}, "linux/arm/v6"},
+		{"linux/arm", []string{"GOOS=linux", "GOARCH=arm", "GOARM=7"}, "linux/arm/v7"},
+		{"linux/arm/v8", []string{"GOOS=linux", "GOARCH=arm", "GOARM=7"}, "linux/arm/v7"},
+		{"linux/amd64", []string{"GOOS=linux", "GOARCH=amd64", "GOAMD64=v1"}, "linux/amd64"},
+		{"linux/amd64/v3", []string{"GOOS=linux", "GOARCH=amd64", "GOAMD64=v3"}, "linux/amd64/v3"},
+		{"linux/arm64", []string{"GOOS=linux", "GOARCH=arm64", "GOARM64=v8.0"}, "linux/arm64"},
+		{"linux/arm64/v8.2", []string{"GOOS=linux", "GOARCH=arm64", "GOARM64=v8.2"}, "linux/arm64/v8.2"},
+		{"linux/arm64/v9", []string{"GOOS=linux", "GOARCH=arm64", "GOARM64=v9.0"}, "linux/arm64/v9"},
+		{"linux/ppc64le/power9", []string{"GOOS=linux", "GOARCH=ppc64le", "GOPPC64=power9"}, "linux/ppc64le/power9"},
+		{"linux/ppc64", []string{"GOOS=linux", "GOARCH=ppc64", "GOPPC64=power8"}, "linux/ppc64"},
+		{"linux/ppc64/power8", []string{"GOOS=linux", "GOARCH=ppc64", "GOPPC64=power8"}, "linux/ppc64"},
+		{"linux/ppc64/power10", []string{"GOOS=linux", "GOARCH=ppc64", "GOPPC64=power10"}, "linux/ppc64/power10"},
+		{"linux/riscv64/rva22u64", []string{"GOOS=linux", "GOARCH=riscv64", "GORISCV64=rva22u64"}, "linux/riscv64/rva22u64"},
+		{"linux/s390x/z15", []string{"GOOS=linux", "GOARCH=s390x"}, "linux/s390x"},
+		{"windows/arm64", []string{"GOOS=windows", "GOARCH=arm64", "GOARM64=v8.0"}, "windows/arm64"},
+		{"darwin/arm64", []string{"GOOS=darwin", "GOARCH=arm64", "GOARM64=v8.0"}, "darwin/arm64"},
+		{"linux/386", []string{"GOOS=linux", "GOARCH=386"}, "linux/386"},
+	} {
+		t.Run(tc.platform, func(t *testing.T) {
+			env, err := GoEnv(MustParse(tc.platform))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(env, tc.env) {
+				t.Errorf("GoEnv(%q) = %v, expected %v", tc.platform, env, tc.env)
+			}
+
+			p, err := FromGoEnv(env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(p, MustParse(tc.expected)) {
+				t.Errorf("FromGoEnv(%v) = %q, expected %q", env, FormatAll(p), tc.expected)
+			}
+		})
+	}
+}
+
+func TestFromGoEnv(t *testing.T) {
+	for _, tc := range []struct {
+		env      []string
+		expected string
+	}{
+		{[]string{"GOOS=linux", "GOARCH=arm"}, "linux/arm/v7"},
+		{[]string{"GOOS=linux", "GOARCH=arm", "GOARM=6,softfloat"}, "linux/arm/v6"},
+		{[]string{"GOOS=linux", "GOARCH=arm64", "GOARM64=v8.1,lse"}, "linux/arm64/v8.1"},
+		{[]string{"GOOS=linux", "GOARCH=amd64", "GOAMD64=v2", "GOAMD64=v4"}, "linux/amd64/v4"},
+		{[]string{"HOME=/root", "GOOS=freebsd", "GOARCH=amd64", "GOARM=5"}, "freebsd/amd64"},
+		{[]string{"GOOS=windows"}, "windows/" + runtime.GOARCH},
+		{[]string{"GOOS=linux", "GOARCH=ppc64", "GOPPC64=power8"}, "linux/ppc64"},
+		{[]string{"GOOS=aix", "GOARCH=ppc64", "GOPPC64=power9"}, "aix/ppc64/power9"},
+	} {
+		p, err := FromGoEnv(tc.env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := Normalize(MustParse(tc.expected)); !Equal(p, expected) {
+			t.Errorf("FromGoEnv(%v) = %q, expected %q", tc.env, FormatAll(p), FormatAll(expected))
+		}
+		if _, err := GoEnv(p); err != nil {
+			t.Errorf("GoEnv(FromGoEnv(%v)): %v", tc.env, err)
+		}
+	}
+}
+
+func TestGoEnvErrors(t *testing.T) {
+	for _, tc := range []struct {
+		platform string
+		reason   error
+	}{
+		{"windows/arm", ErrUnknownPlatform},
+		{"linux/sparc64", ErrUnknownPlatform},
+		{"linux/arm64/v9.6", ErrInvalidVariant},
+		{"linux/ppc64le/power11", ErrInvalidVariant},
+	} {
+		if _, err := GoEnv(MustParse(tc.platform)); !errors.Is(err, tc.reason) {
+			t.Errorf("GoEnv(%q): expected %v, got %v", tc.platform, tc.reason, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		env    []string
+		reason error
+	}{
+		{[]string{"GOOS=haiku", "GOARCH=amd64"}, ErrUnknownPlatform},
+		{[]string{"GOOS=linux", "GOARCH=arm", "GOARM=8"}, ErrInvalidVariant},
+		{[]string{"GOOS=linux", "GOARCH=amd64", "GOAMD64=v5"}, ErrInvalidVariant},
+		{[]string{"GOOS=linux", "GOARCH=arm64", "GOARM64=v9.6"}, ErrInvalidVariant},
+		{[]string{"GOOS=linux", "GOARCH=riscv64", "GORISCV64=rva24u64"}, ErrInvalidVariant},
+	} {
+		if _, err := FromGoEnv(tc.env); !errors.Is(err, tc.reason) {
+			t.Errorf("FromGoEnv(%v): expected %v, got %v", tc.env, tc.reason, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"strings"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Key is the canonical form of a platform. Unlike [specs.Platform], it is
+// comparable, so it can be used as a map key or to compare platforms.
+//
+// Equivalent platforms, such as "linux/aarch64" and "linux/arm64/v8", or
+// platforms only differing in the order of their OS features, have the same
+// key.
+type Key struct {
+	OS           string
+	Architecture string
+	Variant      string
+	OSVersion    string
+
+	// OSFeatures holds the sorted, deduplicated OS features, encoded and
+	// separated by '+' as in the specifier syntax.
+	OSFeatures string
+}
+
+// KeyOf returns the key of the normalized platform.
+func KeyOf(platform specs.Platform) Key {
+	normalized := Normalize(platform)
+	return Key{
+		OS:           normalized.OS,
+		Architecture: normalized.Architecture,
+		Variant:      normalized.Variant,
+		OSVersion:    normalized.OSVersion,
+		OSFeatures:   formatOSFeatures(normalized.OSFeatures),
+	}
+}
+
+// Platform returns the normalized platform of the key.
+func (k Key) Platform() specs.Platform {
+	p := specs.Platform{
+		OS:           k.OS,
+		Architecture: k.Architecture,
+		Variant:      k.Variant,
+		OSVersion:    k.OSVersion,
+	}
+	if k.OSFeatures != "" {
+		for raw := range strings.SplitSeq(k.OSFeatures, "+") {
+			// The features were encoded by KeyOf, so decoding cannot fail.
+			feature, _ := decodeOSOption(raw)
+			p.OSFeatures = append(p.OSFeatures, feature)
+		}
+	}
+	return p
+}
+
+// String returns the platform specifier of the key, as with [FormatAll].
+func (k Key) String() string {
+	return FormatAll(k.Platform())
+}
+
+// Equal returns true if the platforms are equivalent once normalized.
+func Equal(a, b specs.Platform) bool {
+	return KeyOf(a) == KeyOf(b)
+}
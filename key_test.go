@@ -0,0 +1,107 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestEqual(t *testing.T) {
+	for _, tc := range []struct {
+		a, b  specs.Platform
+		equal bool
+	}{
+		{
+			a:     specs.Platform{OS: "linux", Architecture: "aarch64"},
+			b:     specs.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+			equal: true,
+		},
+		{
+			a:     specs.Platform{OS: "Linux", Architecture: "x86_64", Variant: "v1"},
+			b:     specs.Platform{OS: "linux", Architecture: "amd64"},
+			equal: true,
+		},
+		{
+			a:     specs.Platform{OS: "linux", Architecture: "arm"},
+			b:     specs.Platform{OS: "linux", Architecture: "armhf"},
+			equal: true,
+		},
+		{
+			a:     specs.Platform{OS: "linux", Architecture: "amd64", OSFeatures: []string{"simd", "gpu", "gpu"}},
+			b:     specs.Platform{OS: "linux", Architecture: "amd64", OSFeatures: []string{"gpu", "simd"}},
+			equal: true,
+		},
+		{
+			a:     specs.Platform{OS: "linux", Architecture: "arm", Variant: "v6"},
+			b:     specs.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+			equal: false,
+		},
+		{
+			a:     specs.Platform{OS: "windows", Architecture: "amd64", OSVersion: "10.0.17763"},
+			b:     specs.Platform{OS: "windows", Architecture: "amd64", OSVersion: "10.0.20348"},
+			equal: false,
+		},
+		{
+			a:     specs.Platform{OS: "linux", Architecture: "amd64", OSFeatures: []string{"gpu"}},
+			b:     specs.Platform{OS: "linux", Architecture: "amd64"},
+			equal: false,
+		},
+	} {
+		if equal := Equal(tc.a, tc.b); equal != tc.equal {
+			t.Errorf("Equal(%v, %v) should return %v, but returns %v", tc.a, tc.b, tc.equal, equal)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	counts := make(map[Key]int)
+	for _, p := range []specs.Platform{
+		{OS: "linux", Architecture: "aarch64"},
+		{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		{OS: "linux", Architecture: "arm64"},
+		{OS: "linux", Architecture: "amd64"},
+	} {
+		counts[KeyOf(p)]++
+	}
+	expected := map[Key]int{
+		{OS: "linux", Architecture: "arm64"}: 3,
+		{OS: "linux", Architecture: "amd64"}: 1,
+	}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("unexpected counts: %v != %v", counts, expected)
+	}
+
+	p := specs.Platform{
+		OS:           "windows",
+		Architecture: "amd64",
+		OSVersion:    "10.0.17763+build.42",
+		OSFeatures:   []string{"win32k", "feat+v2"},
+	}
+	k := KeyOf(p)
+	if s := k.String(); s != "windows(10.0.17763%2Bbuild.42+feat%2Bv2+win32k)/amd64" {
+		t.Errorf("unexpected key string: %s", s)
+	}
+	if roundTrip := k.Platform(); !reflect.DeepEqual(roundTrip, Normalize(p)) {
+		t.Errorf("key did not survive the round trip: %#v != %#v", roundTrip, Normalize(p))
+	}
+	if KeyOf(MustParse(k.String())) != k {
+		t.Errorf("key did not survive formatting")
+	}
+}
@@ -0,0 +1,104 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"fmt"
+	"strconv"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Well-known labels of Kubernetes nodes describing their platform.
+const (
+	// LabelOS is the operating system of the node, as GOOS.
+	LabelOS = "kubernetes.io/os"
+
+	// LabelArch is the architecture of the node, as GOARCH.
+	LabelArch = "kubernetes.io/arch"
+
+	// LabelWindowsBuild is the Windows build of the node, as
+	// "major.minor.build", such as "10.0.17763".
+	LabelWindowsBuild = "node.kubernetes.io/windows-build"
+)
+
+// ToNodeLabels returns the Kubernetes node labels of the normalized platform.
+//
+// The labels do not include the variant or OS features. The Windows build
+// label is only set for Windows platforms with an OS version.
+func ToNodeLabels(platform specs.Platform) map[string]string {
+	p := Normalize(platform)
+	labels := map[string]string{
+		LabelOS:   p.OS,
+		LabelArch: p.Architecture,
+	}
+	if p.OS == "windows" {
+		if v := getWindowsOSVersion(p.OSVersion); v != (windowsOSVersion{}) {
+			labels[LabelWindowsBuild] = formatWindowsBuild(v)
+		}
+	}
+	return labels
+}
+
+// FromNodeLabels returns the normalized platform of a Kubernetes node from
+// its labels. The OS and architecture labels are required, other labels are
+// ignored.
+//
+// As the labels do not include the variant, it is the default variant of the
+// architecture, such as v7 for arm.
+func FromNodeLabels(labels map[string]string) (specs.Platform, error) {
+	goos, goarch := labels[LabelOS], labels[LabelArch]
+	if goos == "" || goarch == "" {
+		return specs.Platform{}, fmt.Errorf("node labels %s and %s are required: %w", LabelOS, LabelArch, errInvalidArgument)
+	}
+
+	p := Normalize(specs.Platform{OS: goos, Architecture: goarch})
+	if !isKnownOS(p.OS) {
+		return specs.Platform{}, fmt.Errorf("%s=%q: %w", LabelOS, goos, ErrInvalidOS)
+	}
+	if !isKnownArch(p.Architecture) {
+		return specs.Platform{}, fmt.Errorf("%s=%q: %w", LabelArch, goarch, ErrInvalidArchitecture)
+	}
+
+	if build, ok := labels[LabelWindowsBuild]; ok && p.OS == "windows" {
+		v := getWindowsOSVersion(build)
+		if v == (windowsOSVersion{}) {
+			return specs.Platform{}, fmt.Errorf("%s=%q: %w", LabelWindowsBuild, build, ErrInvalidOSVersion)
+		}
+		p.OSVersion = formatWindowsBuild(v)
+	}
+	return p, nil
+}
+
+// NodeMatcher returns a match comparer for the images which can run on a
+// Kubernetes node with the labels, as [Only] would for the platform returned
+// by [FromNodeLabels].
+//
+// On Windows nodes with a build label, the OS version of images must be
+// compatible with the build, as for containerd running on the node.
+func NodeMatcher(labels map[string]string) (MatchComparer, error) {
+	p, err := FromNodeLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	return Only(p), nil
+}
+
+// formatWindowsBuild formats the Windows version as "major.minor.build".
+func formatWindowsBuild(v windowsOSVersion) string {
+	return strconv.Itoa(int(v.MajorVersion)) + "." + strconv.Itoa(int(v.MinorVersion)) + "." + strconv.Itoa(int(v.Build))
+}
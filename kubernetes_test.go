@@ -0,0 +1,140 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNodeLabels(t *testing.T) {
+	for _, tc := range []struct {
+		platform string
+		labels   map[string]string
+		expected string
+	}{
+		{
+			platform: "linux/amd64/v3",
+			labels:   map[string]string{LabelOS: "linux", LabelArch: "amd64"},
+			expected: "linux/amd64",
+		},
+		{
+			platform: "linux/arm/v6",
+			labels:   map[string]string{LabelOS: "linux", LabelArch: "arm"},
+			expected: "linux/arm/v7",
+		},
+		{
+			platform: "linux/aarch64",
+			labels:   map[string]string{LabelOS: "linux", LabelArch: "arm64"},
+			expected: "linux/arm64",
+		},
+		{
+			platform: "windows(10.0.17763.5830)/amd64",
+			labels:   map[string]string{LabelOS: "windows", LabelArch: "amd64", LabelWindowsBuild: "10.0.17763"},
+			expected: "windows(10.0.17763)/amd64",
+		},
+		{
+			platform: "windows/amd64",
+			labels:   map[string]string{LabelOS: "windows", LabelArch: "amd64"},
+			expected: "windows/amd64",
+		},
+	} {
+		t.Run(tc.platform, func(t *testing.T) {
+			labels := ToNodeLabels(MustParse(tc.platform))
+			if !reflect.DeepEqual(labels, tc.labels) {
+				t.Errorf("ToNodeLabels(%q) = %v, expected %v", tc.platform, labels, tc.labels)
+			}
+
+			p, err := FromNodeLabels(labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(p, MustParse(tc.expected)) {
+				t.Errorf("FromNodeLabels(%v) = %q, expected %q", labels, FormatAll(p), tc.expected)
+			}
+		})
+	}
+}
+
+func TestFromNodeLabelsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		labels map[string]string
+		reason error
+	}{
+		{map[string]string{LabelOS: "linux"}, errInvalidArgument},
+		{map[string]string{LabelArch: "amd64"}, errInvalidArgument},
+		{map[string]string{LabelOS: "haiku", LabelArch: "amd64"}, ErrInvalidOS},
+		{map[string]string{LabelOS: "linux", LabelArch: "xtensa"}, ErrInvalidArchitecture},
+		{map[string]string{LabelOS: "windows", LabelArch: "amd64", LabelWindowsBuild: "ltsc2019"}, ErrInvalidOSVersion},
+	} {
+		if _, err := FromNodeLabels(tc.labels); !errors.Is(err, tc.reason) {
+			t.Errorf("FromNodeLabels(%v): expected %v, got %v", tc.labels, tc.reason, err)
+		}
+	}
+}
+
+func TestNodeMatcher(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		labels   map[string]string
+		matches  []string
+		excludes []string
+	}{
+		{
+			name:     "linux arm",
+			labels:   map[string]string{LabelOS: "linux", LabelArch: "arm"},
+			matches:  []string{"linux/arm/v7", "linux/arm/v6", "linux/arm/v5"},
+			excludes: []string{"linux/arm64", "linux/arm/v8", "windows/arm"},
+		},
+		{
+			name:     "linux arm64",
+			labels:   map[string]string{LabelOS: "linux", LabelArch: "arm64", "topology.kubernetes.io/zone": "eu-west-1a"},
+			matches:  []string{"linux/arm64", "linux/arm/v7"},
+			excludes: []string{"linux/amd64", "linux/arm64/v9"},
+		},
+		{
+			name:     "windows server 2019",
+			labels:   map[string]string{LabelOS: "windows", LabelArch: "amd64", LabelWindowsBuild: "10.0.17763"},
+			matches:  []string{"windows(10.0.17763.5830)/amd64", "windows/amd64"},
+			excludes: []string{"windows(10.0.20348.2527)/amd64", "windows(10.0.14393.0)/amd64", "linux/amd64"},
+		},
+		{
+			name:     "windows server 2025",
+			labels:   map[string]string{LabelOS: "windows", LabelArch: "amd64", LabelWindowsBuild: "10.0.26100"},
+			matches:  []string{"windows(10.0.26100.1742)/amd64", "windows(10.0.20348.2527)/amd64"},
+			excludes: []string{"windows(10.0.17763.5830)/amd64"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := NodeMatcher(tc.labels)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tc.matches {
+				if !m.Match(MustParse(s)) {
+					t.Errorf("expected %q to match node %v", s, tc.labels)
+				}
+			}
+			for _, s := range tc.excludes {
+				if m.Match(MustParse(s)) {
+					t.Errorf("expected %q not to match node %v", s, tc.labels)
+				}
+			}
+		})
+	}
+}
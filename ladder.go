@@ -0,0 +1,173 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Ladder returns the platforms which a platform of an architecture can also
+// run, given its normalized variant, in order of preference. Only the
+// architecture and variant of the returned platforms are used.
+//
+// When a returned platform has another architecture, such as 386 for amd64,
+// the ladder of that architecture follows it.
+type Ladder func(variant string) []specs.Platform
+
+var (
+	laddersMu sync.RWMutex
+
+	// ladders holds the ladder of each normalized architecture.
+	ladders = map[string]Ladder{
+		"amd64": amd64Ladder,
+		"arm":   armLadder,
+		"arm64": arm64Ladder,
+		// POWER ISA levels, the baseline is power8
+		"ppc64le": VariantLadder("ppc64le", []string{"", "power9", "power10"}),
+		// RISC-V application profiles, the baseline is rva20u64
+		"riscv64": VariantLadder("riscv64", []string{"", "rva22u64", "rva23u64"}),
+		// IBM Z machine levels, the baseline is z13
+		"s390x": VariantLadder("s390x", []string{"", "z14", "z15", "z16"}),
+	}
+)
+
+// RegisterLadder registers the ladder of the architecture, replacing the
+// ladder previously registered for it, if any. A nil ladder removes it.
+//
+// The ladder is consulted by [Only], [Vector] and the match comparers built on
+// them, such as [Default], created after the registration.
+func RegisterLadder(arch string, ladder Ladder) {
+	arch, _ = normalizeArch(arch, "")
+	laddersMu.Lock()
+	defer laddersMu.Unlock()
+	if ladder == nil {
+		delete(ladders, arch)
+		return
+	}
+	ladders[arch] = ladder
+}
+
+func lookupLadder(arch string) Ladder {
+	laddersMu.RLock()
+	defer laddersMu.RUnlock()
+	return ladders[arch]
+}
+
+// VariantLadder returns the ladder of an architecture whose variants, listed
+// in ascending order, can run the binaries built for the variants before
+// them. The baseline variant, which normalized platforms omit, is the empty
+// variant.
+//
+// Every variant, including those not listed, can also run the fallback
+// platforms, which follow the variants.
+func VariantLadder(arch string, variants []string, fallbacks ...specs.Platform) Ladder {
+	variants = slices.Clone(variants)
+	fallbacks = slices.Clone(fallbacks)
+	return func(variant string) []specs.Platform {
+		var ladder []specs.Platform
+		for i := slices.Index(variants, variant) - 1; i >= 0; i-- {
+			ladder = append(ladder, specs.Platform{Architecture: arch, Variant: variants[i]})
+		}
+		return append(ladder, fallbacks...)
+	}
+}
+
+// Vector returns the platforms matched by [Only] for the normalized platform,
+// in order of preference, starting with the platform itself.
+func Vector(platform specs.Platform) []specs.Platform {
+	return platformVector(Normalize(platform))
+}
+
+// amd64Ladder is the ladder of amd64.
+//
+// For amd64/vN, it is amd64/v{N-1..1}, including unknown levels above v4.
+// All amd64 variants are compatible with 386.
+func amd64Ladder(variant string) []specs.Platform {
+	var ladder []specs.Platform
+	if amd64Version, err := strconv.Atoi(strings.TrimPrefix(variant, "v")); err == nil && amd64Version > 1 {
+		for amd64Version--; amd64Version >= 1; amd64Version-- {
+			amd64Variant := "v" + strconv.Itoa(amd64Version)
+			if amd64Version == 1 {
+				// The baseline is represented without the variant.
+				amd64Variant = ""
+			}
+			ladder = append(ladder, specs.Platform{
+				Architecture: "amd64",
+				Variant:      amd64Variant,
+			})
+		}
+	}
+	return append(ladder, specs.Platform{Architecture: "386"})
+}
+
+// armLadder is the ladder of arm.
+//
+// For arm/vN, it is arm/v{N-1..5}, including unknown versions above v8.
+func armLadder(variant string) []specs.Platform {
+	var ladder []specs.Platform
+	if armVersion, err := strconv.Atoi(strings.TrimPrefix(variant, "v")); err == nil && armVersion > 5 {
+		for armVersion--; armVersion >= 5; armVersion-- {
+			ladder = append(ladder, specs.Platform{
+				Architecture: "arm",
+				Variant:      "v" + strconv.Itoa(armVersion),
+			})
+		}
+	}
+	return ladder
+}
+
+// arm64Ladder is the ladder of arm64.
+//
+// For arm64/v9.x, it is arm64/v9.{0..x-1} and arm64/v8.{0..x+5}.
+// For arm64/v8.x, it is arm64/v8.{0..x-1}.
+// All arm64/v8.x and arm64/v9.x are compatible with arm/v8 (32-bits) and
+// below. Unknown variants have no ladder, so [Only] matches them exactly.
+func arm64Ladder(variant string) []specs.Platform {
+	if variant == "" {
+		variant = "v8"
+	}
+	arm64Versions, ok := arm64variantToVersion[variant]
+	if !ok {
+		return nil
+	}
+
+	var ladder []specs.Platform
+	for i, major := range arm64Versions.major {
+		for minor := arm64Versions.minor[i]; minor >= 0; minor-- {
+			arm64Variant := "v" + strconv.Itoa(major) + "." + strconv.Itoa(minor)
+			if minor == 0 {
+				arm64Variant = "v" + strconv.Itoa(major)
+			}
+			if i == 0 && minor == arm64Versions.minor[i] {
+				// Skip the variant itself.
+				continue
+			}
+			ladder = append(ladder, specs.Platform{
+				Architecture: "arm64",
+				Variant:      arm64Variant,
+			})
+		}
+	}
+
+	// There's no arm v9 variant, so arm64/v9.x falls back to arm/v8 as well.
+	return append(ladder, specs.Platform{Architecture: "arm", Variant: "v8"})
+}
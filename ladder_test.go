@@ -0,0 +1,103 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func formatVector(vector []specs.Platform) []string {
+	formatted := make([]string, len(vector))
+	for i, p := range vector {
+		formatted[i] = FormatAll(p)
+	}
+	return formatted
+}
+
+func TestVector(t *testing.T) {
+	for _, tc := range []struct {
+		platform string
+		expected []string
+	}{
+		{"linux/amd64/v3", []string{"linux/amd64/v3", "linux/amd64/v2", "linux/amd64", "linux/386"}},
+		{"linux/x86_64", []string{"linux/amd64", "linux/386"}},
+		// Unknown levels above the known variants still descend numerically.
+		{"linux/amd64/v5", []string{"linux/amd64/v5", "linux/amd64/v4", "linux/amd64/v3", "linux/amd64/v2", "linux/amd64", "linux/386"}},
+		{"linux/arm/v9", []string{"linux/arm/v9", "linux/arm/v8", "linux/arm/v7", "linux/arm/v6", "linux/arm/v5"}},
+		{"linux/arm/v6", []string{"linux/arm/v6", "linux/arm/v5"}},
+		{"linux/arm64", []string{"linux/arm64", "linux/arm/v8", "linux/arm/v7", "linux/arm/v6", "linux/arm/v5"}},
+		{"linux(+musl)/arm64/v8.2", []string{"linux(+musl)/arm64/v8.2", "linux(+musl)/arm64/v8.1", "linux(+musl)/arm64/v8", "linux(+musl)/arm/v8", "linux(+musl)/arm/v7", "linux(+musl)/arm/v6", "linux(+musl)/arm/v5"}},
+		{"linux/arm64/v9.1", []string{"linux/arm64/v9.1", "linux/arm64/v9", "linux/arm64/v8.6", "linux/arm64/v8.5", "linux/arm64/v8.4", "linux/arm64/v8.3", "linux/arm64/v8.2", "linux/arm64/v8.1", "linux/arm64/v8", "linux/arm/v8", "linux/arm/v7", "linux/arm/v6", "linux/arm/v5"}},
+		{"linux/arm64/v10", []string{"linux/arm64/v10"}},
+		{"linux/s390x/z15", []string{"linux/s390x/z15", "linux/s390x/z14", "linux/s390x"}},
+		{"linux/ppc64le", []string{"linux/ppc64le"}},
+		{"windows(10.0.20348)/amd64", []string{"windows(10.0.20348)/amd64", "windows(10.0.20348)/386"}},
+		{"linux/loong64", []string{"linux/loong64"}},
+		{"linux/*", []string{"linux/*"}},
+	} {
+		t.Run(tc.platform, func(t *testing.T) {
+			actual := formatVector(Vector(MustParse(tc.platform)))
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Wrong vector:\nExpected: %#v\nActual:   %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRegisterLadder(t *testing.T) {
+	t.Cleanup(func() {
+		RegisterLadder("loong64", nil)
+		RegisterLadder("mips64le", nil)
+		RegisterLadder("mipsle", nil)
+	})
+
+	RegisterLadder("loong64", VariantLadder("loong64", []string{"", "la664"}))
+	// Ladders referring to each other are only expanded once, and the
+	// platforms already in the vector are skipped.
+	RegisterLadder("mips64le", VariantLadder("mips64le", nil, specs.Platform{Architecture: "mipsle"}))
+	RegisterLadder("mipsle", VariantLadder("mipsle", nil, specs.Platform{Architecture: "mips64le"}))
+
+	for _, tc := range []struct {
+		platform string
+		expected []string
+	}{
+		{"linux/loong64/la664", []string{"linux/loong64/la664", "linux/loong64"}},
+		{"linux/loong64", []string{"linux/loong64"}},
+		{"linux/mips64le", []string{"linux/mips64le", "linux/mipsle"}},
+		{"linux/mipsle", []string{"linux/mipsle", "linux/mips64le"}},
+	} {
+		actual := formatVector(Vector(MustParse(tc.platform)))
+		if !reflect.DeepEqual(tc.expected, actual) {
+			t.Errorf("Wrong vector for %s:\nExpected: %#v\nActual:   %#v", tc.platform, tc.expected, actual)
+		}
+	}
+
+	m := Only(MustParse("linux/loong64/la664"))
+	for _, s := range []string{"linux/loong64", "linux/loong64/la664"} {
+		if !m.Match(MustParse(s)) {
+			t.Errorf("expected %s to match", s)
+		}
+	}
+
+	RegisterLadder("loong64", nil)
+	if Only(MustParse("linux/loong64/la664")).Match(MustParse("linux/loong64")) {
+		t.Errorf("expected linux/loong64 not to match after removing the ladder")
+	}
+}
@@ -82,9 +82,16 @@ func checkWindowsHostAndContainerCompat(host, ctr windowsOSVersion) bool {
 		return false
 	}
 
+	lowest, highest := windowsCompatibleBuilds(host)
+	return lowest <= ctr.Build && ctr.Build <= highest
+}
+
+// windowsCompatibleBuilds returns the inclusive range of container builds
+// that the host can run.
+func windowsCompatibleBuilds(host windowsOSVersion) (lowest, highest uint16) {
 	// If host is < WS 2022, exact version match is required
 	if host.Build < ltsc2022 {
-		return host.Build == ctr.Build
+		return host.Build, host.Build
 	}
 
 	// Find the latest LTSC version that is earlier than the host version.
@@ -103,7 +110,7 @@ func checkWindowsHostAndContainerCompat(host, ctr windowsOSVersion) bool {
 			break
 		}
 	}
-	return supportedLTSCRelease <= ctr.Build && ctr.Build <= host.Build
+	return supportedLTSCRelease, host.Build
 }
 
 func getWindowsOSVersion(osVersionPrefix string) windowsOSVersion {
@@ -156,12 +163,32 @@ type windowsMatchComparer struct {
 	Matcher
 }
 
+// Rank ranks the matched platforms by OS version, preferring the most
+// recent build and revision. Platforms without an OS version are ranked last.
+func (c *windowsMatchComparer) Rank(p specs.Platform) (int, bool) {
+	if !c.Match(p) {
+		return 0, false
+	}
+	return windowsVersionRank(p.OSVersion), true
+}
+
 func (c *windowsMatchComparer) Less(p1, p2 specs.Platform) bool {
-	m1, m2 := c.Match(p1), c.Match(p2)
-	if m1 && m2 {
-		return p1.OSVersion > p2.OSVersion
+	r1, ok1 := c.Rank(p1)
+	r2, ok2 := c.Rank(p2)
+	return lessRank(r1, ok1, r2, ok2)
+}
+
+// windowsVersionRank returns the rank of a Windows OS version, lower for
+// more recent builds and revisions. The rank fits in 31 bits, with 16 bits
+// for the build and 15 bits for the revision.
+func windowsVersionRank(osVersion string) int {
+	const maxRevision = 1<<15 - 1
+	v := getWindowsOSVersion(osVersion)
+	var revision uint64
+	if parts := strings.SplitN(osVersion, ".", 5); len(parts) == 4 {
+		revision, _ = strconv.ParseUint(parts[3], 10, 64)
 	}
-	return m1 && !m2
+	return int(^v.Build)<<15 | (maxRevision - int(min(revision, maxRevision)))
 }
 
 type windowsStripFeaturesMatcher struct {
@@ -169,8 +196,12 @@ type windowsStripFeaturesMatcher struct {
 }
 
 func (m windowsStripFeaturesMatcher) Match(p specs.Platform) bool {
+	return m.Matcher.Match(stripWin32k(p))
+}
+
+func stripWin32k(p specs.Platform) specs.Platform {
 	if i := slices.Index(p.OSFeatures, "win32k"); i >= 0 {
 		p.OSFeatures = slices.Delete(slices.Clone(p.OSFeatures), i, i+1)
 	}
-	return m.Matcher.Match(p)
+	return p
 }
@@ -85,16 +85,29 @@
 //
 // The following are performed for architectures:
 //
-//	Value    Normalized
-//	aarch64  arm64
-//	armhf    arm
-//	armel    arm/v6
-//	i386     386
-//	x86_64   amd64
-//	x86-64   amd64
+//	Value        Normalized
+//	aarch64      arm64
+//	armhf        arm
+//	armel        arm/v6
+//	i386         386
+//	x86_64       amd64
+//	x86-64       amd64
+//	ppc64el      ppc64le
+//	powerpc64le  ppc64le
 //
 // We also normalize the operating system `macos` to `darwin`.
 //
+// # Wildcards
+//
+// Any component of a specifier may be replaced with the wildcard `*`, for
+// example `linux/*`, `*/arm64`, `linux/arm/*` or `*/*`. A bare `*` is
+// equivalent to `*/*`. The wildcard is preserved in the parsed platform, so
+// the result can be formatted and passed to [NewMatcher], [Only], [Ordered]
+// or [Any] like any other platform. A wildcard component matches any value
+// of that component; a wildcard architecture without an explicit variant
+// also matches any variant. A wildcard OS cannot have an OS version or
+// features.
+//
 // # ARM Support
 //
 // To qualify ARM architecture, the Variant field is used to qualify the arm
@@ -107,9 +120,34 @@
 //
 // While these normalizations are provided, their support on arm platforms has
 // not yet been fully implemented and tested.
+//
+// # RISC-V Support
+//
+// For riscv64, the Variant field holds the RISC-V application profile, such as
+// rva22u64 or rva23u64. The baseline profile, rva20u64, is represented without
+// the variant. The short forms rva20, rva22 and rva23 are normalized to their
+// full profile names.
+//
+// # POWER Support
+//
+// For ppc64le and ppc64, the Variant field holds the POWER ISA level, such as
+// power9 or power10. The baseline level, power8, is represented without the
+// variant.
+//
+// # IBM Z Support
+//
+// For s390x, the Variant field holds the machine level, such as z15 or z16.
+// The baseline level, z13, is represented without the variant.
+//
+// # Other Architectures
+//
+// The variants and fallback architectures which [Only] matches for an
+// architecture are described by its [Ladder]. Ladders for other
+// architectures, or other variants, may be registered with [RegisterLadder].
 package platforms
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"path"
@@ -118,13 +156,17 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"unicode"
 
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
+// wildcard matches any value of the platform component it is used in.
+const wildcard = "*"
+
 var (
-	specifierRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
-	osRe        = regexp.MustCompile(`^([A-Za-z0-9_-]+)(?:\(([A-Za-z0-9_.%-]*)((?:\+[A-Za-z0-9_.%-]+)*)\))?$`)
+	specifierRe = regexp.MustCompile(`^(?:[A-Za-z0-9_.-]+|\*)$`)
+	osRe        = regexp.MustCompile(`^([A-Za-z0-9_-]+|\*)(?:\(([A-Za-z0-9_.%-]*)((?:\+[A-Za-z0-9_.%-]+)*)\))?$`)
 )
 
 // Platform is a type alias for convenience, so there is no need to import image-spec package everywhere.
@@ -188,39 +230,51 @@ type matcher struct {
 
 func (m *matcher) Match(platform specs.Platform) bool {
 	normalized := Normalize(platform)
-	if m.OS == normalized.OS &&
-		m.Architecture == normalized.Architecture &&
-		m.Variant == normalized.Variant &&
-		m.matchOSVersion(platform) {
-		if len(normalized.OSFeatures) == 0 {
-			return true
-		}
-		if len(m.OSFeatures) >= len(normalized.OSFeatures) {
-			// Ensure that normalized.OSFeatures is a subset of
-			// m.OSFeatures
-			j := 0
-			for _, feature := range normalized.OSFeatures {
-				found := false
-				for ; j < len(m.OSFeatures); j++ {
-					if feature == m.OSFeatures[j] {
-						found = true
-						j++
-						break
-					}
-					// Since both lists are ordered, if the feature is less
-					// than what is seen, it is not in the list
-					if feature < m.OSFeatures[j] {
-						return false
-					}
-				}
-				if !found {
-					return false
-				}
+	return matchComponent(m.OS, normalized.OS) &&
+		matchComponent(m.Architecture, normalized.Architecture) &&
+		m.matchVariant(normalized.Variant) &&
+		m.matchOSVersion(platform) &&
+		isOSFeaturesSubset(m.OSFeatures, normalized.OSFeatures)
+}
+
+// isOSFeaturesSubset returns true if subset only contains features which are
+// also in features. Both lists must be sorted.
+func isOSFeaturesSubset(features, subset []string) bool {
+	if len(subset) == 0 {
+		return true
+	}
+	if len(features) < len(subset) {
+		return false
+	}
+	j := 0
+	for _, feature := range subset {
+		found := false
+		for ; j < len(features); j++ {
+			if feature == features[j] {
+				found = true
+				j++
+				break
+			}
+			// Since both lists are ordered, if the feature is less
+			// than what is seen, it is not in the list
+			if feature < features[j] {
+				return false
 			}
-			return true
+		}
+		if !found {
+			return false
 		}
 	}
-	return false
+	return true
+}
+
+// matchVariant matches the variant, treating a wildcard architecture
+// without a variant as matching any variant.
+func (m *matcher) matchVariant(variant string) bool {
+	if m.Architecture == wildcard && m.Variant == "" {
+		return true
+	}
+	return matchComponent(m.Variant, variant)
 }
 
 func (m *matcher) matchOSVersion(platform specs.Platform) bool {
@@ -234,6 +288,12 @@ func (m *matcher) String() string {
 	return FormatAll(m.Platform)
 }
 
+// matchComponent returns true if the value matches the expected platform
+// component, which may be a wildcard.
+func matchComponent(expected, value string) bool {
+	return expected == wildcard || expected == value
+}
+
 // ParseAll parses a list of platform specifiers into a list of platform.
 func ParseAll(specifiers []string) ([]specs.Platform, error) {
 	platforms := make([]specs.Platform, len(specifiers))
@@ -247,6 +307,118 @@ func ParseAll(specifiers []string) ([]specs.Platform, error) {
 	return platforms, nil
 }
 
+// ParseList parses a list of platform specifiers separated by commas or
+// whitespace, such as "linux/amd64,linux/arm64, linux/arm/v7".
+//
+// Equivalent specifiers, such as "linux/arm64" and "linux/aarch64/v8", are
+// only returned once, in the position of the first occurrence. When any
+// specifier is invalid, an error is returned reporting every invalid
+// specifier.
+func ParseList(list string) ([]specs.Platform, error) {
+	platforms, err := parseList(list)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		unique []specs.Platform
+		seen   = make(map[Key]struct{})
+	)
+	for _, p := range platforms {
+		key := KeyOf(p)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, p)
+	}
+	return unique, nil
+}
+
+// parseList parses a list of platform specifiers as with [ParseList], without
+// removing equivalent specifiers.
+func parseList(list string) ([]specs.Platform, error) {
+	var (
+		platforms []specs.Platform
+		errs      []error
+	)
+	for _, s := range strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		p, err := Parse(s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		platforms = append(platforms, p)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return platforms, nil
+}
+
+// ParseOrdered parses a list of platform specifiers, as with [ParseList], into
+// a MatchComparer preferring the platforms in the order they are listed, as
+// with [Ordered].
+func ParseOrdered(list string) (MatchComparer, error) {
+	platforms, err := ParseList(list)
+	if err != nil {
+		return nil, err
+	}
+	return Ordered(platforms...), nil
+}
+
+// ParseFilters parses a list of platform specifiers into the platforms to
+// include and the platforms to exclude. A specifier prefixed with '!' is an
+// exclusion, such as `!windows` or `!linux/386`.
+//
+// Unlike [Parse], components missing from an exclusion are not inferred from
+// the local environment, they are treated as wildcards. So `!windows` excludes
+// windows on every architecture, `!arm64` excludes arm64 on every operating
+// system and `!linux/arm` excludes every variant of arm on linux.
+//
+// The result may be passed to [Exclude] to obtain a [MatchComparer].
+func ParseFilters(specifiers []string) (include, exclude []specs.Platform, err error) {
+	for _, s := range specifiers {
+		if negated, ok := strings.CutPrefix(s, "!"); ok {
+			p, err := parseExclusion(negated)
+			if err != nil {
+				return nil, nil, err
+			}
+			exclude = append(exclude, p)
+			continue
+		}
+		p, err := Parse(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		include = append(include, p)
+	}
+	return include, exclude, nil
+}
+
+// parseExclusion parses a specifier, using wildcards rather than the local
+// environment or the default variant for a missing operating system,
+// architecture or variant.
+func parseExclusion(specifier string) (specs.Platform, error) {
+	p, err := Parse(specifier)
+	if err != nil {
+		return p, err
+	}
+	components := strings.Count(specifier, "/") + 1
+	if components == 1 {
+		if osOptions := osRe.FindStringSubmatch(specifier); osOptions != nil && isKnownOS(normalizeOS(osOptions[1])) {
+			p.Architecture, p.Variant = wildcard, ""
+			return p, nil
+		}
+		p.OS = wildcard
+	}
+	if components < 3 && p.Architecture != wildcard {
+		p.Variant = wildcard
+	}
+	return p, nil
+}
+
 // Parse parses the platform specifier syntax into a platform declaration.
 //
 // Platform specifiers are in the format `<os>[(<os options>)]|<arch>|<os>[(<os options>)]/<arch>[/<variant>]`.
@@ -261,41 +433,84 @@ func ParseAll(specifiers []string) ([]specs.Platform, error) {
 // value will be matched against the known set of operating systems, then fall
 // back to the known set of architectures. The missing component will be
 // inferred based on the local environment.
+//
+// Any component may be the wildcard `*`, which is kept as-is in the returned
+// platform. A single `*` is parsed as `*/*`.
 func Parse(specifier string) (specs.Platform, error) {
-	if strings.Contains(specifier, "*") {
-		// TODO(stevvooe): need to work out exact wildcard handling
-		return specs.Platform{}, fmt.Errorf("%q: wildcards not yet supported: %w", specifier, errInvalidArgument)
-	}
-
 	// Limit to 4 elements to prevent unbounded split
 	parts := strings.SplitN(specifier, "/", 4)
 
-	var p specs.Platform
+	var (
+		p      specs.Platform
+		offset int
+	)
 	for i, part := range parts {
-		if i == 0 {
+		switch i {
+		case 0:
 			// First element is <os>[(<OSVersion>[+<OSFeature>]*)]
-			osOptions := osRe.FindStringSubmatch(part)
+			osOptions := osRe.FindStringSubmatchIndex(part)
 			if osOptions == nil {
-				return specs.Platform{}, fmt.Errorf("%q is an invalid OS component of %q: OSAndVersion specifier component must match %q: %w", part, specifier, osRe.String(), errInvalidArgument)
+				return specs.Platform{}, &ParseError{
+					Specifier: specifier,
+					Component: part,
+					Reason:    ErrInvalidOS,
+					Err:       fmt.Errorf("OSAndVersion specifier component must match %q", osRe.String()),
+				}
 			}
 
-			p.OS = normalizeOS(osOptions[1])
-			osVersion, err := decodeOSOption(osOptions[2])
-			if err != nil {
-				return specs.Platform{}, fmt.Errorf("%q has an invalid OS version %q: %w", specifier, osOptions[2], err)
+			p.OS = normalizeOS(part[osOptions[2]:osOptions[3]])
+			if p.OS == wildcard && osOptions[4] >= 0 {
+				return specs.Platform{}, &ParseError{
+					Specifier: specifier,
+					Component: part,
+					Reason:    ErrInvalidOS,
+					Err:       errors.New("wildcard OS cannot have an OS version or features"),
+				}
+			}
+			if osOptions[4] >= 0 {
+				rawVersion := part[osOptions[4]:osOptions[5]]
+				osVersion, err := decodeOSOption(rawVersion)
+				if err != nil {
+					return specs.Platform{}, &ParseError{
+						Specifier: specifier,
+						Component: rawVersion,
+						Offset:    osOptions[4],
+						Reason:    ErrInvalidOSVersion,
+						Err:       err,
+					}
+				}
+				p.OSVersion = osVersion
 			}
-			p.OSVersion = osVersion
-			if osOptions[3] != "" {
-				p.OSFeatures, err = parseOSFeatures(osOptions[3][1:])
+			if osOptions[6] >= 0 && osOptions[6] < osOptions[7] {
+				var err error
+				p.OSFeatures, err = parseOSFeatures(specifier, osOptions[6]+1, part[osOptions[6]+1:osOptions[7]])
 				if err != nil {
-					return specs.Platform{}, fmt.Errorf("%q has invalid OS features: %w", specifier, err)
+					return specs.Platform{}, err
 				}
 			}
-		} else {
+		case 1, 2:
 			if !specifierRe.MatchString(part) {
-				return specs.Platform{}, fmt.Errorf("%q is an invalid component of %q: platform specifier component must match %q: %w", part, specifier, specifierRe.String(), errInvalidArgument)
+				reason := ErrInvalidArchitecture
+				if i == 2 {
+					reason = ErrInvalidVariant
+				}
+				return specs.Platform{}, &ParseError{
+					Specifier: specifier,
+					Component: part,
+					Offset:    offset,
+					Reason:    reason,
+					Err:       fmt.Errorf("platform specifier component must match %q", specifierRe.String()),
+				}
+			}
+		default:
+			return specs.Platform{}, &ParseError{
+				Specifier: specifier,
+				Component: part,
+				Offset:    offset,
+				Reason:    ErrTooManyComponents,
 			}
 		}
+		offset += len(part) + 1
 	}
 
 	switch len(parts) {
@@ -306,6 +521,10 @@ func Parse(specifier string) (specs.Platform, error) {
 		// we have very little information about the platform here, we are
 		// going to be a little more strict if we don't know about the argument
 		// value.
+		if p.OS == wildcard {
+			p.Architecture = wildcard
+			return p, nil
+		}
 		if isKnownOS(p.OS) {
 			// picks a default architecture
 			p.Architecture = runtime.GOARCH
@@ -325,7 +544,11 @@ func Parse(specifier string) (specs.Platform, error) {
 			return p, nil
 		}
 
-		return specs.Platform{}, fmt.Errorf("%q: unknown operating system or architecture: %w", specifier, errInvalidArgument)
+		return specs.Platform{}, &ParseError{
+			Specifier: specifier,
+			Component: specifier,
+			Reason:    ErrUnknownPlatform,
+		}
 	case 2:
 		// In this case, we treat as a regular OS[(OSVersion)]/arch pair. We don't care
 		// about whether or not we know of the platform.
@@ -335,8 +558,9 @@ func Parse(specifier string) (specs.Platform, error) {
 		}
 
 		return p, nil
-	case 3:
-		// we have a fully specified variant, this is rare
+	default:
+		// we have a fully specified variant, this is rare. More components
+		// were rejected above.
 		p.Architecture, p.Variant = normalizeArch(parts[1], parts[2])
 		if p.Architecture == "arm64" && p.Variant == "" {
 			p.Variant = "v8"
@@ -344,25 +568,37 @@ func Parse(specifier string) (specs.Platform, error) {
 
 		return p, nil
 	}
-
-	return specs.Platform{}, fmt.Errorf("%q: cannot parse platform specifier: %w", specifier, errInvalidArgument)
 }
 
-func parseOSFeatures(s string) ([]string, error) {
+// parseOSFeatures parses the '+' separated OS features found at offset in the
+// specifier.
+func parseOSFeatures(specifier string, offset int, s string) ([]string, error) {
 	if s == "" {
 		return nil, nil
 	}
 
 	var features []string
 	for raw := range strings.SplitSeq(s, "+") {
-		raw = strings.TrimSpace(raw)
-		if raw == "" {
-			return nil, fmt.Errorf("empty os feature: %w", errInvalidArgument)
+		if strings.TrimSpace(raw) == "" {
+			return nil, &ParseError{
+				Specifier: specifier,
+				Component: raw,
+				Offset:    offset,
+				Reason:    ErrInvalidOSFeature,
+				Err:       errors.New("empty os feature"),
+			}
 		}
-		feature, err := decodeOSOption(raw)
+		feature, err := decodeOSOption(strings.TrimSpace(raw))
 		if err != nil {
-			return nil, fmt.Errorf("invalid os feature %q: %w", raw, err)
+			return nil, &ParseError{
+				Specifier: specifier,
+				Component: raw,
+				Offset:    offset,
+				Reason:    ErrInvalidOSFeature,
+				Err:       err,
+			}
 		}
+		offset += len(raw) + 1
 		if feature == "" {
 			continue
 		}
@@ -17,6 +17,7 @@
 package platforms
 
 import (
+	"errors"
 	"path"
 	"reflect"
 	"runtime"
@@ -39,19 +40,13 @@ func TestParseSelector(t *testing.T) {
 	}
 
 	for _, testcase := range []struct {
-		skip        bool
 		input       string
 		expected    specs.Platform
 		matches     []specs.Platform
 		formatted   string
 		useV2Format bool
 	}{
-		// While wildcards are a valid use case for platform selection,
-		// addressing these cases is outside the initial scope for this
-		// package. When we do add platform wildcards, we should add in these
-		// testcases to ensure that they are correctly represented.
 		{
-			skip:  true,
 			input: "*",
 			expected: specs.Platform{
 				OS:           "*",
@@ -61,7 +56,6 @@ func TestParseSelector(t *testing.T) {
 			useV2Format: false,
 		},
 		{
-			skip:  true,
 			input: "linux/*",
 			expected: specs.Platform{
 				OS:           "linux",
@@ -71,7 +65,6 @@ func TestParseSelector(t *testing.T) {
 			useV2Format: false,
 		},
 		{
-			skip:  true,
 			input: "*/arm64",
 			expected: specs.Platform{
 				OS:           "*",
@@ -96,6 +89,31 @@ func TestParseSelector(t *testing.T) {
 			formatted:   "*/arm64",
 			useV2Format: false,
 		},
+		{
+			input: "linux/arm/*",
+			expected: specs.Platform{
+				OS:           "linux",
+				Architecture: "arm",
+				Variant:      "*",
+			},
+			matches: []specs.Platform{
+				{
+					OS:           "linux",
+					Architecture: "arm",
+				},
+				{
+					OS:           "linux",
+					Architecture: "armel",
+				},
+				{
+					OS:           "linux",
+					Architecture: "arm",
+					Variant:      "v8",
+				},
+			},
+			formatted:   "linux/arm/*",
+			useV2Format: false,
+		},
 		{
 			input: "linux/arm64",
 			expected: specs.Platform{
@@ -302,6 +320,64 @@ func TestParseSelector(t *testing.T) {
 			formatted:   "linux/s390x",
 			useV2Format: false,
 		},
+		{
+			input: "ppc64el",
+			expected: specs.Platform{
+				OS:           defaultOS,
+				Architecture: "ppc64le",
+			},
+			formatted:   path.Join(defaultOS, "ppc64le"),
+			useV2Format: false,
+		},
+		{
+			input: "linux/powerpc64le/power9",
+			expected: specs.Platform{
+				OS:           "linux",
+				Architecture: "ppc64le",
+				Variant:      "power9",
+			},
+			matches: []specs.Platform{
+				{
+					OS:           "linux",
+					Architecture: "ppc64el",
+					Variant:      "POWER9",
+				},
+			},
+			formatted:   "linux/ppc64le/power9",
+			useV2Format: false,
+		},
+		{
+			input: "linux/riscv64/rva23",
+			expected: specs.Platform{
+				OS:           "linux",
+				Architecture: "riscv64",
+				Variant:      "rva23u64",
+			},
+			matches: []specs.Platform{
+				{
+					OS:           "linux",
+					Architecture: "riscv64",
+					Variant:      "RVA23U64",
+				},
+			},
+			formatted:   "linux/riscv64/rva23u64",
+			useV2Format: false,
+		},
+		{
+			input: "linux/riscv64/rva20u64",
+			expected: specs.Platform{
+				OS:           "linux",
+				Architecture: "riscv64",
+			},
+			matches: []specs.Platform{
+				{
+					OS:           "linux",
+					Architecture: "riscv64",
+				},
+			},
+			formatted:   "linux/riscv64",
+			useV2Format: false,
+		},
 		{
 			input: "macOS",
 			expected: specs.Platform{
@@ -429,9 +505,6 @@ func TestParseSelector(t *testing.T) {
 		},
 	} {
 		t.Run(testcase.input, func(t *testing.T) {
-			if testcase.skip {
-				t.Skip("this case is not yet supported")
-			}
 			p, err := Parse(testcase.input)
 			if err != nil {
 				t.Fatal(err)
@@ -571,6 +644,18 @@ func TestParseSelectorInvalid(t *testing.T) {
 		{
 			input: "linux/arm/foo/bar", // too many components
 		},
+		{
+			input: "linux/arm*", // partial wildcard
+		},
+		{
+			input: "**", // partial wildcard
+		},
+		{
+			input: "*(10.0.17763)/amd64", // OS version on a wildcard OS
+		},
+		{
+			input: "*(+gpu)", // OS features on a wildcard OS
+		},
 	} {
 		t.Run(testcase.input, func(t *testing.T) {
 			if _, err := Parse(testcase.input); err == nil {
@@ -580,6 +665,176 @@ func TestParseSelectorInvalid(t *testing.T) {
 	}
 }
 
+func TestParseError(t *testing.T) {
+	for _, testcase := range []struct {
+		input     string
+		reason    error
+		component string
+		offset    int
+	}{
+		{
+			input:     "",
+			reason:    ErrInvalidOS,
+			component: "",
+			offset:    0,
+		},
+		{
+			input:     "linux&/amd64",
+			reason:    ErrInvalidOS,
+			component: "linux&",
+			offset:    0,
+		},
+		{
+			input:     "windows(10.0%zz)/amd64",
+			reason:    ErrInvalidOSVersion,
+			component: "10.0%zz",
+			offset:    8,
+		},
+		{
+			input:     "linux(+gpu+bad%zz)/amd64",
+			reason:    ErrInvalidOSFeature,
+			component: "bad%zz",
+			offset:    11,
+		},
+		{
+			input:     "*(10.0.17763)/amd64",
+			reason:    ErrInvalidOS,
+			component: "*(10.0.17763)",
+			offset:    0,
+		},
+		{
+			input:     "linux/&arm",
+			reason:    ErrInvalidArchitecture,
+			component: "&arm",
+			offset:    6,
+		},
+		{
+			input:     "linux/arm/v7&",
+			reason:    ErrInvalidVariant,
+			component: "v7&",
+			offset:    10,
+		},
+		{
+			input:     "linux/arm/v7/foo",
+			reason:    ErrTooManyComponents,
+			component: "foo",
+			offset:    13,
+		},
+		{
+			input:     "nonsense",
+			reason:    ErrUnknownPlatform,
+			component: "nonsense",
+			offset:    0,
+		},
+	} {
+		t.Run(testcase.input, func(t *testing.T) {
+			_, err := Parse(testcase.input)
+			if !errors.Is(err, testcase.reason) {
+				t.Fatalf("expected %v, got %v", testcase.reason, err)
+			}
+			if !errors.Is(err, errInvalidArgument) {
+				t.Errorf("expected %v to be an invalid argument", err)
+			}
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected a *ParseError, got %T", err)
+			}
+			if perr.Specifier != testcase.input {
+				t.Errorf("unexpected specifier: %q != %q", perr.Specifier, testcase.input)
+			}
+			if perr.Component != testcase.component || perr.Offset != testcase.offset {
+				t.Errorf("unexpected component: %q at %d != %q at %d", perr.Component, perr.Offset, testcase.component, testcase.offset)
+			}
+			if testcase.input[perr.Offset:perr.Offset+len(perr.Component)] != perr.Component {
+				t.Errorf("component %q is not at offset %d of %q", perr.Component, perr.Offset, testcase.input)
+			}
+		})
+	}
+
+	// Errors are preserved when parsing lists.
+	_, err := ParseAll([]string{"linux/amd64", "linux/&arm"})
+	if !errors.Is(err, ErrInvalidArchitecture) {
+		t.Errorf("expected %v, got %v", ErrInvalidArchitecture, err)
+	}
+}
+
+func TestParseList(t *testing.T) {
+	platforms, err := ParseList("linux/amd64,linux/arm64, linux/arm/v7\tlinux/aarch64/v8,,linux/arm linux/x86_64 linux(+gpu)/amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []specs.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm64"},
+		{OS: "linux", Architecture: "arm", Variant: "v7"},
+		{OS: "linux", Architecture: "amd64", OSFeatures: []string{"gpu"}},
+	}
+	if !reflect.DeepEqual(platforms, expected) {
+		t.Errorf("unexpected platforms: %#v != %#v", platforms, expected)
+	}
+
+	_, err = ParseList("linux/amd64, linux/&arm, nonsense,linux/arm64")
+	if err == nil {
+		t.Fatal("should have received an error")
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Specifier != "linux/&arm" {
+		t.Errorf("expected the first error to be for linux/&arm, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidArchitecture) || !errors.Is(err, ErrUnknownPlatform) {
+		t.Errorf("expected all invalid platforms to be reported, got %v", err)
+	}
+	if n := strings.Count(err.Error(), "linux/&arm"); n != 1 {
+		t.Errorf("expected the specifier to be quoted once, got %d times: %v", n, err)
+	}
+
+	m, err := ParseOrdered("linux/arm64 linux/amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Less(MustParse("linux/arm64"), MustParse("linux/amd64")) {
+		t.Errorf("expected linux/arm64 to be preferred")
+	}
+	if m.Match(MustParse("linux/386")) {
+		t.Errorf("expected linux/386 not to match")
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	include, exclude, err := ParseFilters([]string{"linux/*", "!linux/386", "!windows", "!arm64", "darwin/arm64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedInclude := []specs.Platform{
+		{OS: "linux", Architecture: "*"},
+		{OS: "darwin", Architecture: "arm64"},
+	}
+	if !reflect.DeepEqual(include, expectedInclude) {
+		t.Errorf("unexpected include: %#v != %#v", include, expectedInclude)
+	}
+
+	expectedExclude := []specs.Platform{
+		{OS: "linux", Architecture: "386", Variant: "*"},
+		{OS: "windows", Architecture: "*"},
+		{OS: "*", Architecture: "arm64", Variant: "*"},
+	}
+	if !reflect.DeepEqual(exclude, expectedExclude) {
+		t.Errorf("unexpected exclude: %#v != %#v", exclude, expectedExclude)
+	}
+
+	for _, input := range []string{"!", "!!linux", "!linux/&arm", "linux/&arm"} {
+		_, _, err := ParseFilters([]string{input})
+		if err == nil {
+			t.Errorf("%q should have received an error", input)
+			continue
+		}
+		if specifier := strings.TrimPrefix(input, "!"); specifier != "" && strings.Count(err.Error(), specifier) != 1 {
+			t.Errorf("expected %q to be quoted once: %v", specifier, err)
+		}
+	}
+}
+
 func TestFormatAllSkipsEmptyOSFeatures(t *testing.T) {
 	p := specs.Platform{
 		OS:           "linux",
@@ -0,0 +1,145 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+
+	"github.com/containerd/log"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// HostProbe provides the information about a host used to detect its default
+// platform. The host running the process is probed by [DefaultSpec]; other
+// hosts may be described to [DefaultSpecFrom] and [DefaultFrom], for example
+// to test platform detection.
+type HostProbe interface {
+	// Runtime returns the operating system and architecture of the host,
+	// as GOOS and GOARCH values.
+	Runtime() (goos, goarch string)
+
+	// MachineArch returns the machine hardware name, as reported by uname.
+	MachineArch() (string, error)
+
+	// CPUInfo returns a reader for the contents of /proc/cpuinfo. It is
+	// only used on Linux.
+	CPUInfo() (io.ReadCloser, error)
+
+	// OSVersion returns the version of the operating system. It is only
+	// used on Windows.
+	OSVersion() string
+}
+
+// FSProbe is a HostProbe reading the CPU information of a host from a file
+// system, such as os.DirFS("/") or an [testing/fstest.MapFS], where the CPU
+// information is read from "proc/cpuinfo".
+type FSProbe struct {
+	// FS is the root file system of the host.
+	FS fs.FS
+
+	// OS is the operating system of the host, as a GOOS value.
+	OS string
+
+	// Architecture is the architecture of the host, as a GOARCH value.
+	Architecture string
+
+	// Machine is the machine hardware name of the host, as reported by
+	// uname, such as "armv7l" or "aarch64".
+	Machine string
+
+	// Version is the version of the operating system of the host.
+	Version string
+}
+
+// Runtime returns the operating system and architecture of the host.
+func (p FSProbe) Runtime() (string, string) {
+	return p.OS, p.Architecture
+}
+
+// MachineArch returns the machine hardware name of the host.
+func (p FSProbe) MachineArch() (string, error) {
+	if p.Machine == "" {
+		return "", fmt.Errorf("machine architecture: %w", errNotFound)
+	}
+	return p.Machine, nil
+}
+
+// CPUInfo opens "proc/cpuinfo" in the file system of the host.
+func (p FSProbe) CPUInfo() (io.ReadCloser, error) {
+	if p.FS == nil {
+		return nil, fmt.Errorf("cpuinfo: %w", errNotFound)
+	}
+	return p.FS.Open("proc/cpuinfo")
+}
+
+// OSVersion returns the version of the operating system of the host.
+func (p FSProbe) OSVersion() string {
+	return p.Version
+}
+
+// hostProbe probes the host running the process.
+type hostProbe struct{}
+
+func (hostProbe) Runtime() (string, string) {
+	return runtime.GOOS, runtime.GOARCH
+}
+
+func (hostProbe) MachineArch() (string, error) {
+	return getMachineArch()
+}
+
+func (hostProbe) CPUInfo() (io.ReadCloser, error) {
+	return os.Open("/proc/cpuinfo")
+}
+
+func (hostProbe) OSVersion() string {
+	return getOSVersion()
+}
+
+// DefaultSpecFrom returns the default platform specification of the host
+// described by the probe. Unlike [DefaultSpec], the result is not cached.
+func DefaultSpecFrom(probe HostProbe) specs.Platform {
+	goos, goarch := probe.Runtime()
+	p := specs.Platform{
+		OS:           goos,
+		Architecture: goarch,
+		OSVersion:    probe.OSVersion(),
+	}
+	if hasCPUVariant(goarch) {
+		var err error
+		p.Variant, err = getCPUVariant(probe)
+		if err != nil {
+			log.L.Errorf("Error getCPUVariant for OS %s: %v", goos, err)
+		}
+	}
+	return p
+}
+
+// DefaultFrom returns the default matcher of the host described by the probe.
+func DefaultFrom(probe HostProbe) MatchComparer {
+	return defaultMatchComparer(DefaultSpecFrom(probe))
+}
+
+// DefaultStrictFrom returns the strict default matcher of the host described
+// by the probe, as [DefaultStrict].
+func DefaultStrictFrom(probe HostProbe) MatchComparer {
+	return defaultStrictMatchComparer(DefaultSpecFrom(probe))
+}
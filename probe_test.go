@@ -0,0 +1,251 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func cpuinfoFS(cpuinfo string) fstest.MapFS {
+	return fstest.MapFS{
+		"proc/cpuinfo": &fstest.MapFile{Data: []byte(cpuinfo)},
+	}
+}
+
+func TestDefaultSpecFrom(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		probe    FSProbe
+		expected specs.Platform
+		matches  map[bool][]string
+	}{
+		{
+			name: "raspberry pi v6",
+			probe: FSProbe{
+				FS: cpuinfoFS("processor\t: 0\n" +
+					"model name\t: ARMv6-compatible processor rev 7 (v6l)\n" +
+					"Features\t: half thumb fastmult vfp edsp java tls\n" +
+					"CPU architecture: 7\n"),
+				OS:           "linux",
+				Architecture: "arm",
+				Machine:      "armv6l",
+			},
+			expected: specs.Platform{OS: "linux", Architecture: "arm", Variant: "v6"},
+			matches: map[bool][]string{
+				true:  {"linux/arm/v6", "linux/arm/v5"},
+				false: {"linux/arm/v7", "linux/arm64"},
+			},
+		},
+		{
+			name: "emulated arm without cpu architecture",
+			probe: FSProbe{
+				FS:           cpuinfoFS("processor\t: 0\n"),
+				OS:           "linux",
+				Architecture: "arm",
+				Machine:      "armv7l",
+			},
+			expected: specs.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+		},
+		{
+			name: "graviton3",
+			probe: FSProbe{
+				FS: cpuinfoFS("processor\t: 0\n" +
+					"BogoMIPS\t: 2100.00\n" +
+					"Features\t: fp asimd evtstrm aes pmull sha1 sha2 crc32 atomics fphp asimdhp cpuid asimdrdm jscvt fcma lrcpc dcpop sha3 sm3 sm4 asimddp sha512 sve asimdfhm dit uscat ilrcpc flagm ssbs paca pacg dcpodp svei8mm svebf16 i8mm bf16 dgh rng\n" +
+					"CPU implementer\t: 0x41\n" +
+					"CPU architecture: 8\n"),
+				OS:           "linux",
+				Architecture: "arm64",
+				Machine:      "aarch64",
+			},
+			expected: specs.Platform{OS: "linux", Architecture: "arm64", Variant: "v8.4"},
+			matches: map[bool][]string{
+				true:  {"linux/arm64/v8.4", "linux/arm64", "linux/arm/v7"},
+				false: {"linux/arm64/v8.5", "linux/amd64"},
+			},
+		},
+		{
+			name: "amd64 v3",
+			probe: FSProbe{
+				FS: cpuinfoFS("processor\t: 0\n" +
+					"flags\t\t: fpu vme de pse tsc msr pae mce cx8 apic sep mtrr pge mca cmov pat pse36 clflush mmx fxsr sse sse2 syscall nx lm pni ssse3 fma cx16 sse4_1 sse4_2 movbe popcnt xsave avx f16c lahf_lm abm bmi1 avx2 bmi2\n"),
+				OS:           "linux",
+				Architecture: "amd64",
+				Machine:      "x86_64",
+			},
+			expected: specs.Platform{OS: "linux", Architecture: "amd64", Variant: "v3"},
+			matches: map[bool][]string{
+				true:  {"linux/amd64/v3", "linux/amd64/v2", "linux/amd64", "linux/386"},
+				false: {"linux/amd64/v4", "darwin/amd64"},
+			},
+		},
+		{
+			name: "apple silicon",
+			probe: FSProbe{
+				OS:           "darwin",
+				Architecture: "arm64",
+			},
+			expected: specs.Platform{OS: "darwin", Architecture: "arm64", Variant: "v8"},
+			matches: map[bool][]string{
+				true:  {"darwin/arm64", "linux/arm64"},
+				false: {"linux/amd64", "windows/arm64"},
+			},
+		},
+		{
+			name: "windows server 2025",
+			probe: FSProbe{
+				OS:           "windows",
+				Architecture: "amd64",
+				Version:      "10.0.26100",
+			},
+			expected: specs.Platform{OS: "windows", Architecture: "amd64", OSVersion: "10.0.26100"},
+			matches: map[bool][]string{
+				true:  {"windows(10.0.26100)/amd64", "windows(10.0.20348)/amd64", "windows/amd64"},
+				false: {"windows(10.0.17763)/amd64", "linux/amd64"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := DefaultSpecFrom(tc.probe)
+			if !reflect.DeepEqual(p, tc.expected) {
+				t.Fatalf("default platform not as expected: %#v != %#v", p, tc.expected)
+			}
+
+			m := DefaultFrom(tc.probe)
+			for shouldMatch, platforms := range tc.matches {
+				for _, matchPlatform := range platforms {
+					mp, err := Parse(matchPlatform)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if match := m.Match(mp); shouldMatch != match {
+						t.Errorf("DefaultFrom().Match(%q) should return %v, but returns %v", matchPlatform, shouldMatch, match)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultStrictFrom(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		probe   FSProbe
+		matches map[bool][]string
+	}{
+		{
+			name: "amd64 v3",
+			probe: FSProbe{
+				FS: cpuinfoFS("processor\t: 0\n" +
+					"flags\t\t: fpu vme de pse tsc msr pae mce cx8 apic sep mtrr pge mca cmov pat pse36 clflush mmx fxsr sse sse2 syscall nx lm pni ssse3 fma cx16 sse4_1 sse4_2 movbe popcnt xsave avx f16c lahf_lm abm bmi1 avx2 bmi2\n"),
+				OS:           "linux",
+				Architecture: "amd64",
+				Machine:      "x86_64",
+			},
+			matches: map[bool][]string{
+				true:  {"linux/amd64", "linux/amd64/v1"},
+				false: {"linux/amd64/v3", "linux/386"},
+			},
+		},
+		{
+			name: "graviton3",
+			probe: FSProbe{
+				FS: cpuinfoFS("processor\t: 0\n" +
+					"Features\t: fp asimd evtstrm aes pmull sha1 sha2 crc32 atomics fphp asimdhp cpuid asimdrdm jscvt fcma lrcpc dcpop sha3 sm3 sm4 asimddp sha512 sve asimdfhm dit uscat ilrcpc flagm ssbs paca pacg dcpodp svei8mm svebf16 i8mm bf16 dgh rng\n" +
+					"CPU architecture: 8\n"),
+				OS:           "linux",
+				Architecture: "arm64",
+				Machine:      "aarch64",
+			},
+			matches: map[bool][]string{
+				true:  {"linux/arm64", "linux/arm64/v8"},
+				false: {"linux/arm64/v8.4", "linux/arm/v7"},
+			},
+		},
+		{
+			name: "raspberry pi v6",
+			probe: FSProbe{
+				FS: cpuinfoFS("processor\t: 0\n" +
+					"model name\t: ARMv6-compatible processor rev 7 (v6l)\n" +
+					"CPU architecture: 7\n"),
+				OS:           "linux",
+				Architecture: "arm",
+				Machine:      "armv6l",
+			},
+			matches: map[bool][]string{
+				true:  {"linux/arm/v6"},
+				false: {"linux/arm/v7", "linux/arm/v5"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := DefaultStrictFrom(tc.probe)
+			for shouldMatch, platforms := range tc.matches {
+				for _, matchPlatform := range platforms {
+					if match := m.Match(MustParse(matchPlatform)); shouldMatch != match {
+						t.Errorf("DefaultStrictFrom().Match(%q) should return %v, but returns %v", matchPlatform, shouldMatch, match)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultSpecFromIncompleteProbe(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		probe    FSProbe
+		expected specs.Platform
+	}{
+		{
+			name: "short machine without cpu architecture",
+			probe: FSProbe{
+				FS:           cpuinfoFS("processor\t: 0\n"),
+				OS:           "linux",
+				Architecture: "arm",
+				Machine:      "arm",
+			},
+			expected: specs.Platform{OS: "linux", Architecture: "arm"},
+		},
+		{
+			name: "no file system",
+			probe: FSProbe{
+				OS:           "linux",
+				Architecture: "amd64",
+			},
+			expected: specs.Platform{OS: "linux", Architecture: "amd64"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := DefaultSpecFrom(tc.probe)
+			if !reflect.DeepEqual(p, tc.expected) {
+				t.Fatalf("default platform not as expected: %#v != %#v", p, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFSProbeWithoutFS(t *testing.T) {
+	if _, err := (FSProbe{}).CPUInfo(); !errors.Is(err, errNotFound) {
+		t.Errorf("expected %v, got %v", errNotFound, err)
+	}
+}
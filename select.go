@@ -0,0 +1,90 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"slices"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Filter returns the descriptors with a platform matched by m, such as the
+// manifests of an image index, in their original order. Descriptors without
+// a platform are never matched.
+func Filter(m Matcher, descs []specs.Descriptor) []specs.Descriptor {
+	var filtered []specs.Descriptor
+	for _, desc := range descs {
+		if desc.Platform != nil && m.Match(*desc.Platform) {
+			filtered = append(filtered, desc)
+		}
+	}
+	return filtered
+}
+
+// Sort sorts the descriptors in place, most preferred by m first. The sort is
+// stable, so descriptors which m does not order keep their original order.
+// Descriptors without a platform are sorted last.
+func Sort(m MatchComparer, descs []specs.Descriptor) {
+	slices.SortStableFunc(descs, func(a, b specs.Descriptor) int {
+		switch {
+		case a.Platform == nil && b.Platform == nil:
+			return 0
+		case a.Platform == nil:
+			return 1
+		case b.Platform == nil:
+			return -1
+		case m.Less(*a.Platform, *b.Platform):
+			return -1
+		case m.Less(*b.Platform, *a.Platform):
+			return 1
+		}
+		return 0
+	})
+}
+
+// SelectBest returns the descriptor with the platform most preferred by m,
+// which is the first descriptor after filtering with [Filter] and sorting
+// with [Sort]. If no descriptor matches, false is returned.
+//
+// When m is a [Ranker], the platform of each descriptor is only ranked once.
+func SelectBest(m MatchComparer, descs []specs.Descriptor) (specs.Descriptor, bool) {
+	var (
+		best  specs.Descriptor
+		found bool
+	)
+	if r, ok := m.(Ranker); ok {
+		var bestRank int
+		for _, desc := range descs {
+			if desc.Platform == nil {
+				continue
+			}
+			if rank, ok := r.Rank(*desc.Platform); ok && (!found || rank < bestRank) {
+				best, bestRank, found = desc, rank, true
+			}
+		}
+		return best, found
+	}
+	for _, desc := range descs {
+		if desc.Platform == nil || !m.Match(*desc.Platform) {
+			continue
+		}
+		if !found || m.Less(*desc.Platform, *best.Platform) {
+			best, found = desc, true
+		}
+	}
+	return best, found
+}
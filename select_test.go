@@ -0,0 +1,131 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func testDescriptors(t *testing.T, platforms ...string) []specs.Descriptor {
+	t.Helper()
+	descs := make([]specs.Descriptor, len(platforms))
+	for i, s := range platforms {
+		descs[i] = specs.Descriptor{
+			MediaType: specs.MediaTypeImageManifest,
+			Size:      int64(i),
+		}
+		if s == "" {
+			continue
+		}
+		p, err := Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		descs[i].Platform = &p
+	}
+	return descs
+}
+
+func descriptorPlatforms(descs []specs.Descriptor) []string {
+	actual := make([]string, len(descs))
+	for i, desc := range descs {
+		if desc.Platform != nil {
+			actual[i] = FormatAll(*desc.Platform)
+		}
+	}
+	return actual
+}
+
+func TestFilter(t *testing.T) {
+	descs := testDescriptors(t, "linux/arm64", "", "linux/amd64", "windows/amd64", "linux/386")
+	filtered := Filter(Only(MustParse("linux/amd64")), descs)
+	expected := []string{"linux/amd64", "linux/386"}
+	if actual := descriptorPlatforms(filtered); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Wrong filtered platforms:\nExpected: %#v\nActual:   %#v", expected, actual)
+	}
+}
+
+func TestSort(t *testing.T) {
+	descs := testDescriptors(t, "", "linux/386", "windows/amd64", "linux/amd64", "linux/arm64", "linux/amd64")
+	Sort(Only(MustParse("linux/amd64")), descs)
+	expected := []string{"linux/amd64", "linux/amd64", "linux/386", "windows/amd64", "linux/arm64", ""}
+	if actual := descriptorPlatforms(descs); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Wrong platform order:\nExpected: %#v\nActual:   %#v", expected, actual)
+	}
+	// ties keep their original order
+	if descs[0].Size != 3 || descs[1].Size != 5 {
+		t.Errorf("sort is not stable: %d, %d", descs[0].Size, descs[1].Size)
+	}
+}
+
+func TestSelectBest(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		mc        MatchComparer
+		platforms []string
+		expected  int
+	}{
+		{
+			name:      "preferred",
+			mc:        Only(MustParse("linux/amd64/v3")),
+			platforms: []string{"linux/386", "", "linux/amd64/v2", "linux/amd64/v3", "linux/amd64"},
+			expected:  3,
+		},
+		{
+			name:      "first of ties",
+			mc:        Any(MustParse("linux/amd64"), MustParse("linux/arm64")),
+			platforms: []string{"windows/amd64", "linux/arm64", "linux/amd64"},
+			expected:  1,
+		},
+		{
+			name:      "no match",
+			mc:        Only(MustParse("linux/s390x")),
+			platforms: []string{"linux/amd64", ""},
+			expected:  -1,
+		},
+		{
+			name:     "empty",
+			mc:       All,
+			expected: -1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			descs := testDescriptors(t, tc.platforms...)
+			best, ok := SelectBest(tc.mc, descs)
+			if tc.expected < 0 {
+				if ok {
+					t.Fatalf("expected no match, got %v", best.Platform)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("expected a match")
+			}
+			if best.Size != descs[tc.expected].Size {
+				t.Errorf("expected %s, got %s", FormatAll(*descs[tc.expected].Platform), FormatAll(*best.Platform))
+			}
+
+			Sort(tc.mc, descs)
+			if sorted := descs[0]; sorted.Size != best.Size {
+				t.Errorf("SelectBest does not agree with Sort: %s != %s", FormatAll(*best.Platform), FormatAll(*sorted.Platform))
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Set is a set of normalized platforms. Equivalent platforms, as determined
+// by [Equal], are only stored once.
+//
+// The zero value is an empty set ready to use. A nil *Set is treated as an
+// empty set by every method except Add.
+type Set struct {
+	keys map[Key]struct{}
+}
+
+// NewSet returns a set of the provided platforms.
+func NewSet(platforms ...specs.Platform) *Set {
+	s := &Set{}
+	s.Add(platforms...)
+	return s
+}
+
+// Add adds the platforms to the set.
+func (s *Set) Add(platforms ...specs.Platform) {
+	if s.keys == nil {
+		s.keys = make(map[Key]struct{}, len(platforms))
+	}
+	for _, p := range platforms {
+		s.keys[KeyOf(p)] = struct{}{}
+	}
+}
+
+// Remove removes the platforms from the set.
+func (s *Set) Remove(platforms ...specs.Platform) {
+	for _, p := range platforms {
+		delete(s.entries(), KeyOf(p))
+	}
+}
+
+// Len returns the number of platforms in the set.
+func (s *Set) Len() int {
+	return len(s.entries())
+}
+
+// Contains returns true if the set contains a platform equivalent to the
+// provided platform.
+func (s *Set) Contains(platform specs.Platform) bool {
+	_, ok := s.entries()[KeyOf(platform)]
+	return ok
+}
+
+// ContainsMatch returns true if the set contains a platform matched by m.
+func (s *Set) ContainsMatch(m Matcher) bool {
+	for k := range s.entries() {
+		if m.Match(k.Platform()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns a new set of the platforms in the set matched by m.
+func (s *Set) Select(m Matcher) *Set {
+	selected := &Set{}
+	for k := range s.entries() {
+		if m.Match(k.Platform()) {
+			selected.add(k)
+		}
+	}
+	return selected
+}
+
+// Union returns a new set of the platforms in either set.
+func (s *Set) Union(other *Set) *Set {
+	union := &Set{}
+	for k := range s.entries() {
+		union.add(k)
+	}
+	for k := range other.entries() {
+		union.add(k)
+	}
+	return union
+}
+
+// Intersect returns a new set of the platforms in both sets.
+func (s *Set) Intersect(other *Set) *Set {
+	intersection := &Set{}
+	for k := range s.entries() {
+		if _, ok := other.entries()[k]; ok {
+			intersection.add(k)
+		}
+	}
+	return intersection
+}
+
+// Difference returns a new set of the platforms in the set which are not in
+// the other set.
+func (s *Set) Difference(other *Set) *Set {
+	difference := &Set{}
+	for k := range s.entries() {
+		if _, ok := other.entries()[k]; !ok {
+			difference.add(k)
+		}
+	}
+	return difference
+}
+
+func (s *Set) add(k Key) {
+	if s.keys == nil {
+		s.keys = make(map[Key]struct{})
+	}
+	s.keys[k] = struct{}{}
+}
+
+// entries returns the keys of the set, nil for a nil set.
+func (s *Set) entries() map[Key]struct{} {
+	if s == nil {
+		return nil
+	}
+	return s.keys
+}
+
+// All returns an iterator over the normalized platforms of the set, ordered
+// by their platform specifier.
+func (s *Set) All() iter.Seq[specs.Platform] {
+	return slices.Values(s.Sorted(All))
+}
+
+// Sorted returns the normalized platforms of the set, most preferred by m
+// first. Platforms which m does not order are ordered by their platform
+// specifier.
+func (s *Set) Sorted(m MatchComparer) []specs.Platform {
+	keys := slices.SortedFunc(maps.Keys(s.entries()), func(a, b Key) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	platforms := make([]specs.Platform, len(keys))
+	for i, k := range keys {
+		platforms[i] = k.Platform()
+	}
+	slices.SortStableFunc(platforms, func(a, b specs.Platform) int {
+		switch {
+		case m.Less(a, b):
+			return -1
+		case m.Less(b, a):
+			return 1
+		}
+		return 0
+	})
+	return platforms
+}
+
+// String returns the comma separated platform specifiers of the set, ordered
+// as with [Set.All].
+func (s *Set) String() string {
+	var b strings.Builder
+	for p := range s.All() {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(FormatAll(p))
+	}
+	return b.String()
+}
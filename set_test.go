@@ -0,0 +1,159 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestSet(t *testing.T, list string) *Set {
+	t.Helper()
+	platforms, err := ParseList(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSet(platforms...)
+}
+
+func TestSet(t *testing.T) {
+	var s Set
+	s.Add(MustParse("linux/aarch64"), MustParse("linux/arm64/v8"), MustParse("linux/amd64"))
+	if s.Len() != 2 {
+		t.Errorf("expected 2 platforms, got %d: %s", s.Len(), s.String())
+	}
+	if !s.Contains(MustParse("linux/arm64")) {
+		t.Errorf("expected set to contain linux/arm64")
+	}
+	if s.Contains(MustParse("linux/386")) {
+		t.Errorf("expected set not to contain linux/386")
+	}
+	if !s.ContainsMatch(Only(MustParse("linux/arm64/v8.2"))) {
+		t.Errorf("expected set to contain a platform matching linux/arm64/v8.2")
+	}
+	if s.ContainsMatch(OnlyOS(MustParse("windows/amd64"))) {
+		t.Errorf("expected set not to contain a platform matching windows")
+	}
+	s.Remove(MustParse("linux/x86_64"))
+	if s.String() != "linux/arm64" {
+		t.Errorf("unexpected set: %s", s.String())
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	source := newTestSet(t, "linux/amd64 linux/arm64 linux/arm/v7 linux/s390x windows(10.0.20348)/amd64")
+	mirror := newTestSet(t, "linux/x86_64 linux/aarch64/v8 linux/riscv64")
+
+	for _, tc := range []struct {
+		name     string
+		set      *Set
+		expected string
+	}{
+		{
+			name:     "union",
+			set:      source.Union(mirror),
+			expected: "linux/amd64,linux/arm/v7,linux/arm64,linux/riscv64,linux/s390x,windows(10.0.20348)/amd64",
+		},
+		{
+			name:     "intersect",
+			set:      source.Intersect(mirror),
+			expected: "linux/amd64,linux/arm64",
+		},
+		{
+			name:     "difference",
+			set:      source.Difference(mirror),
+			expected: "linux/arm/v7,linux/s390x,windows(10.0.20348)/amd64",
+		},
+		{
+			name:     "select",
+			set:      source.Select(Only(MustParse("linux/arm64"))),
+			expected: "linux/arm/v7,linux/arm64",
+		},
+		{
+			name:     "empty",
+			set:      (&Set{}).Union(&Set{}),
+			expected: "",
+		},
+		{
+			name:     "union nil",
+			set:      source.Union(nil),
+			expected: "linux/amd64,linux/arm/v7,linux/arm64,linux/s390x,windows(10.0.20348)/amd64",
+		},
+		{
+			name:     "nil union",
+			set:      (*Set)(nil).Union(mirror),
+			expected: "linux/amd64,linux/arm64,linux/riscv64",
+		},
+		{
+			name:     "intersect nil",
+			set:      source.Intersect(nil),
+			expected: "",
+		},
+		{
+			name:     "difference nil",
+			set:      source.Difference(nil),
+			expected: "linux/amd64,linux/arm/v7,linux/arm64,linux/s390x,windows(10.0.20348)/amd64",
+		},
+		{
+			name:     "nil difference",
+			set:      (*Set)(nil).Difference(mirror),
+			expected: "",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if s := tc.set.String(); s != tc.expected {
+				t.Errorf("unexpected set: %s != %s", s, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSetNil(t *testing.T) {
+	var s *Set
+	if n := s.Len(); n != 0 {
+		t.Errorf("expected an empty set, got %d platforms", n)
+	}
+	if s.Contains(MustParse("linux/amd64")) {
+		t.Errorf("expected a nil set not to contain linux/amd64")
+	}
+	if s.ContainsMatch(All) {
+		t.Errorf("expected a nil set not to contain any platform")
+	}
+	s.Remove(MustParse("linux/amd64"))
+	if n := s.Select(All).Len(); n != 0 {
+		t.Errorf("expected an empty selection, got %d platforms", n)
+	}
+	if platforms := s.Sorted(All); len(platforms) != 0 {
+		t.Errorf("expected no platforms, got %v", platforms)
+	}
+	if str := s.String(); str != "" {
+		t.Errorf("expected an empty string, got %q", str)
+	}
+}
+
+func TestSetSorted(t *testing.T) {
+	s := newTestSet(t, "linux/s390x linux/arm/v7 linux/amd64 linux/arm64 linux/386")
+	sorted := s.Sorted(Only(MustParse("linux/amd64")))
+	actual := make([]string, len(sorted))
+	for i, p := range sorted {
+		actual[i] = Format(p)
+	}
+	expected := []string{"linux/amd64", "linux/386", "linux/arm/v7", "linux/arm64", "linux/s390x"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Wrong platform order:\nExpected: %#v\nActual:   %#v", expected, actual)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"fmt"
+	"slices"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Toolchain identifies the target naming scheme of a compiler toolchain.
+type Toolchain string
+
+const (
+	// GNU target triplets, as with [ToTriple] and [FromTriple].
+	GNU Toolchain = "gnu"
+
+	// Rust target names, such as "aarch64-unknown-linux-musl".
+	Rust Toolchain = "rust"
+
+	// LLVM target triples, such as "arm64-apple-macosx".
+	LLVM Toolchain = "llvm"
+
+	// Zig targets, such as "x86_64-linux-gnu".
+	Zig Toolchain = "zig"
+)
+
+// toolchainTarget holds the target names of a platform.
+//
+// The key only holds the OS, architecture, arm variant and the
+// [OSFeatureMusl] feature of the platform. Other variants and OS features,
+// as well as the OS version, are not represented in target names.
+type toolchainTarget struct {
+	key  Key
+	rust string
+	llvm string
+	zig  string
+}
+
+// toolchainTargets is the table of target names. When several platforms have
+// the same target name, the first one is used when parsing it.
+//
+// Zig targets do not include the arm variant, which is chosen with the CPU
+// instead, and use the GNU ABI on Windows.
+var toolchainTargets = []toolchainTarget{
+	{Key{OS: "linux", Architecture: "amd64"}, "x86_64-unknown-linux-gnu", "x86_64-unknown-linux-gnu", "x86_64-linux-gnu"},
+	{Key{OS: "linux", Architecture: "amd64", OSFeatures: OSFeatureMusl}, "x86_64-unknown-linux-musl", "x86_64-unknown-linux-musl", "x86_64-linux-musl"},
+	{Key{OS: "linux", Architecture: "386"}, "i686-unknown-linux-gnu", "i686-unknown-linux-gnu", "x86-linux-gnu"},
+	{Key{OS: "linux", Architecture: "386", OSFeatures: OSFeatureMusl}, "i686-unknown-linux-musl", "i686-unknown-linux-musl", "x86-linux-musl"},
+	{Key{OS: "linux", Architecture: "arm64"}, "aarch64-unknown-linux-gnu", "aarch64-unknown-linux-gnu", "aarch64-linux-gnu"},
+	{Key{OS: "linux", Architecture: "arm64", OSFeatures: OSFeatureMusl}, "aarch64-unknown-linux-musl", "aarch64-unknown-linux-musl", "aarch64-linux-musl"},
+	{Key{OS: "linux", Architecture: "arm", Variant: "v7"}, "armv7-unknown-linux-gnueabihf", "armv7-unknown-linux-gnueabihf", "arm-linux-gnueabihf"},
+	{Key{OS: "linux", Architecture: "arm", Variant: "v7", OSFeatures: OSFeatureMusl}, "armv7-unknown-linux-musleabihf", "armv7-unknown-linux-musleabihf", "arm-linux-musleabihf"},
+	{Key{OS: "linux", Architecture: "arm", Variant: "v6"}, "arm-unknown-linux-gnueabihf", "armv6-unknown-linux-gnueabihf", "arm-linux-gnueabihf"},
+	{Key{OS: "linux", Architecture: "arm", Variant: "v6", OSFeatures: OSFeatureMusl}, "arm-unknown-linux-musleabihf", "armv6-unknown-linux-musleabihf", "arm-linux-musleabihf"},
+	{Key{OS: "linux", Architecture: "arm", Variant: "v5"}, "armv5te-unknown-linux-gnueabi", "armv5te-unknown-linux-gnueabi", "arm-linux-gnueabi"},
+	{Key{OS: "linux", Architecture: "arm", Variant: "v5", OSFeatures: OSFeatureMusl}, "armv5te-unknown-linux-musleabi", "armv5te-unknown-linux-musleabi", "arm-linux-musleabi"},
+	{Key{OS: "linux", Architecture: "ppc64le"}, "powerpc64le-unknown-linux-gnu", "powerpc64le-unknown-linux-gnu", "powerpc64le-linux-gnu"},
+	{Key{OS: "linux", Architecture: "ppc64le", OSFeatures: OSFeatureMusl}, "powerpc64le-unknown-linux-musl", "powerpc64le-unknown-linux-musl", "powerpc64le-linux-musl"},
+	{Key{OS: "linux", Architecture: "ppc64"}, "powerpc64-unknown-linux-gnu", "powerpc64-unknown-linux-gnu", "powerpc64-linux-gnu"},
+	{Key{OS: "linux", Architecture: "s390x"}, "s390x-unknown-linux-gnu", "s390x-unknown-linux-gnu", "s390x-linux-gnu"},
+	{Key{OS: "linux", Architecture: "s390x", OSFeatures: OSFeatureMusl}, "s390x-unknown-linux-musl", "s390x-unknown-linux-musl", "s390x-linux-musl"},
+	{Key{OS: "linux", Architecture: "riscv64"}, "riscv64gc-unknown-linux-gnu", "riscv64-unknown-linux-gnu", "riscv64-linux-gnu"},
+	{Key{OS: "linux", Architecture: "riscv64", OSFeatures: OSFeatureMusl}, "riscv64gc-unknown-linux-musl", "riscv64-unknown-linux-musl", "riscv64-linux-musl"},
+	{Key{OS: "linux", Architecture: "loong64"}, "loongarch64-unknown-linux-gnu", "loongarch64-unknown-linux-gnu", "loongarch64-linux-gnu"},
+	{Key{OS: "linux", Architecture: "loong64", OSFeatures: OSFeatureMusl}, "loongarch64-unknown-linux-musl", "loongarch64-unknown-linux-musl", "loongarch64-linux-musl"},
+	{Key{OS: "linux", Architecture: "mips64le"}, "mips64el-unknown-linux-gnuabi64", "mips64el-unknown-linux-gnuabi64", "mips64el-linux-gnuabi64"},
+	{Key{OS: "linux", Architecture: "mips64le", OSFeatures: OSFeatureMusl}, "mips64el-unknown-linux-muslabi64", "mips64el-unknown-linux-muslabi64", "mips64el-linux-muslabi64"},
+	{Key{OS: "android", Architecture: "arm64"}, "aarch64-linux-android", "aarch64-unknown-linux-android", "aarch64-linux-android"},
+	{Key{OS: "android", Architecture: "amd64"}, "x86_64-linux-android", "x86_64-unknown-linux-android", "x86_64-linux-android"},
+	{Key{OS: "android", Architecture: "arm", Variant: "v7"}, "armv7-linux-androideabi", "armv7-unknown-linux-androideabi", "arm-linux-androideabi"},
+	{Key{OS: "darwin", Architecture: "amd64"}, "x86_64-apple-darwin", "x86_64-apple-macosx", "x86_64-macos"},
+	{Key{OS: "darwin", Architecture: "arm64"}, "aarch64-apple-darwin", "arm64-apple-macosx", "aarch64-macos"},
+	{Key{OS: "windows", Architecture: "amd64"}, "x86_64-pc-windows-msvc", "x86_64-pc-windows-msvc", "x86_64-windows-gnu"},
+	{Key{OS: "windows", Architecture: "arm64"}, "aarch64-pc-windows-msvc", "aarch64-pc-windows-msvc", "aarch64-windows-gnu"},
+	{Key{OS: "windows", Architecture: "386"}, "i686-pc-windows-msvc", "i686-pc-windows-msvc", "x86-windows-gnu"},
+	{Key{OS: "freebsd", Architecture: "amd64"}, "x86_64-unknown-freebsd", "x86_64-unknown-freebsd", "x86_64-freebsd"},
+	{Key{OS: "freebsd", Architecture: "arm64"}, "aarch64-unknown-freebsd", "aarch64-unknown-freebsd", "aarch64-freebsd"},
+}
+
+// name returns the target name for the toolchain.
+func (t toolchainTarget) name(toolchain Toolchain) string {
+	switch toolchain {
+	case Rust:
+		return t.rust
+	case LLVM:
+		return t.llvm
+	case Zig:
+		return t.zig
+	}
+	return ""
+}
+
+// toolchainKey returns the key of the normalized platform in the target
+// table.
+func toolchainKey(p specs.Platform) Key {
+	k := Key{OS: p.OS, Architecture: p.Architecture}
+	if p.Architecture == "arm" {
+		k.Variant = p.Variant
+	}
+	if p.OS == "linux" && slices.Contains(p.OSFeatures, OSFeatureMusl) {
+		k.OSFeatures = OSFeatureMusl
+	}
+	return k
+}
+
+// ToTarget returns the target name of the normalized platform for the
+// toolchain, such as "aarch64-unknown-linux-musl" for "linux(+musl)/arm64"
+// with [Rust].
+//
+// Variants other than those of arm are not represented in target names, nor
+// are the OS version and OS features other than [OSFeatureMusl]. An error is
+// returned when the toolchain has no target for the platform.
+func ToTarget(toolchain Toolchain, platform specs.Platform) (string, error) {
+	if toolchain == GNU {
+		return ToTriple(platform)
+	}
+	if toolchain != Rust && toolchain != LLVM && toolchain != Zig {
+		return "", fmt.Errorf("unknown toolchain %q: %w", toolchain, errInvalidArgument)
+	}
+
+	p := Normalize(platform)
+	k := toolchainKey(p)
+	for _, t := range toolchainTargets {
+		if t.key == k {
+			return t.name(toolchain), nil
+		}
+	}
+	return "", fmt.Errorf("%q: %w: no %s target", FormatAll(p), ErrUnknownPlatform, toolchain)
+}
+
+// FromTarget returns the normalized platform of a target name of the
+// toolchain, such as "linux/arm/v7" for "armv7-unknown-linux-gnueabihf" with
+// [Rust].
+//
+// Only the exact target names returned by [ToTarget] are known. As Zig
+// targets do not include the arm variant, "arm-linux-gnueabihf" is arm/v7.
+func FromTarget(toolchain Toolchain, target string) (specs.Platform, error) {
+	if toolchain == GNU {
+		return FromTriple(target)
+	}
+	if toolchain != Rust && toolchain != LLVM && toolchain != Zig {
+		return specs.Platform{}, fmt.Errorf("unknown toolchain %q: %w", toolchain, errInvalidArgument)
+	}
+
+	for _, t := range toolchainTargets {
+		if t.name(toolchain) == target {
+			return t.key.Platform(), nil
+		}
+	}
+	return specs.Platform{}, fmt.Errorf("%q: %w: unknown %s target", target, errInvalidArgument, toolchain)
+}
@@ -0,0 +1,110 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTarget(t *testing.T) {
+	for _, tc := range []struct {
+		platform string
+		rust     string
+		llvm     string
+		zig      string
+	}{
+		{"linux/amd64", "x86_64-unknown-linux-gnu", "x86_64-unknown-linux-gnu", "x86_64-linux-gnu"},
+		{"linux(+musl)/arm64", "aarch64-unknown-linux-musl", "aarch64-unknown-linux-musl", "aarch64-linux-musl"},
+		{"linux/arm/v7", "armv7-unknown-linux-gnueabihf", "armv7-unknown-linux-gnueabihf", "arm-linux-gnueabihf"},
+		{"linux/386", "i686-unknown-linux-gnu", "i686-unknown-linux-gnu", "x86-linux-gnu"},
+		{"linux/riscv64", "riscv64gc-unknown-linux-gnu", "riscv64-unknown-linux-gnu", "riscv64-linux-gnu"},
+		{"linux(+musl)/s390x", "s390x-unknown-linux-musl", "s390x-unknown-linux-musl", "s390x-linux-musl"},
+		{"android/arm64", "aarch64-linux-android", "aarch64-unknown-linux-android", "aarch64-linux-android"},
+		{"darwin/arm64", "aarch64-apple-darwin", "arm64-apple-macosx", "aarch64-macos"},
+		{"windows/amd64", "x86_64-pc-windows-msvc", "x86_64-pc-windows-msvc", "x86_64-windows-gnu"},
+	} {
+		t.Run(tc.platform, func(t *testing.T) {
+			p := MustParse(tc.platform)
+			for toolchain, expected := range map[Toolchain]string{Rust: tc.rust, LLVM: tc.llvm, Zig: tc.zig} {
+				target, err := ToTarget(toolchain, p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if target != expected {
+					t.Errorf("ToTarget(%s, %q) = %q, expected %q", toolchain, tc.platform, target, expected)
+				}
+
+				parsed, err := FromTarget(toolchain, target)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !Equal(parsed, p) {
+					t.Errorf("FromTarget(%s, %q) = %q, expected %q", toolchain, target, FormatAll(parsed), tc.platform)
+				}
+			}
+		})
+	}
+}
+
+func TestTargetNormalization(t *testing.T) {
+	for _, tc := range []struct {
+		toolchain Toolchain
+		platform  string
+		target    string
+	}{
+		// Variants other than arm's and unrelated features are dropped.
+		{Rust, "linux/amd64/v3", "x86_64-unknown-linux-gnu"},
+		{Rust, "linux(+musl+fips)/arm64/v8.2", "aarch64-unknown-linux-musl"},
+		{LLVM, "linux/aarch64", "aarch64-unknown-linux-gnu"},
+		{LLVM, "linux/arm/v6", "armv6-unknown-linux-gnueabihf"},
+		{Zig, "linux/arm/v6", "arm-linux-gnueabihf"},
+		{GNU, "linux(+musl)/arm64", "aarch64-linux-musl"},
+	} {
+		target, err := ToTarget(tc.toolchain, MustParse(tc.platform))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if target != tc.target {
+			t.Errorf("ToTarget(%s, %q) = %q, expected %q", tc.toolchain, tc.platform, target, tc.target)
+		}
+	}
+
+	// Zig targets do not include the arm variant.
+	p, err := FromTarget(Zig, "arm-linux-gnueabihf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := MustParse("linux/arm/v7"); !Equal(p, expected) {
+		t.Errorf("FromTarget(zig, \"arm-linux-gnueabihf\") = %q, expected %q", FormatAll(p), FormatAll(expected))
+	}
+}
+
+func TestTargetErrors(t *testing.T) {
+	if _, err := ToTarget(Rust, MustParse("plan9/amd64")); !errors.Is(err, ErrUnknownPlatform) {
+		t.Errorf("expected %v, got %v", ErrUnknownPlatform, err)
+	}
+	if _, err := ToTarget("go", MustParse("linux/amd64")); !errors.Is(err, errInvalidArgument) {
+		t.Errorf("expected %v, got %v", errInvalidArgument, err)
+	}
+	if _, err := FromTarget(Zig, "x86_64-unknown-linux-gnu"); !errors.Is(err, errInvalidArgument) {
+		t.Errorf("expected %v, got %v", errInvalidArgument, err)
+	}
+	if _, err := FromTarget("go", "x86_64-linux-gnu"); !errors.Is(err, errInvalidArgument) {
+		t.Errorf("expected %v, got %v", errInvalidArgument, err)
+	}
+}
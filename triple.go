@@ -0,0 +1,196 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// OSFeatureMusl is the OS feature of Linux platforms using the musl C library.
+// Linux platforms without it use the GNU C library.
+const OSFeatureMusl = "musl"
+
+// tripleArchs maps normalized architectures to their name in GNU target
+// triplets. The variant of arm is added to the name, other variants are not
+// represented in triplets.
+var tripleArchs = map[string]string{
+	"386":      "i686",
+	"amd64":    "x86_64",
+	"arm":      "arm",
+	"arm64":    "aarch64",
+	"loong64":  "loongarch64",
+	"mips":     "mips",
+	"mipsle":   "mipsel",
+	"mips64":   "mips64",
+	"mips64le": "mips64el",
+	"ppc64":    "powerpc64",
+	"ppc64le":  "powerpc64le",
+	"riscv64":  "riscv64",
+	"s390x":    "s390x",
+}
+
+// ToTriple returns the GNU target triplet of the normalized platform, such as
+// "aarch64-linux-gnu" for "linux/arm64" or "armv7-linux-gnueabihf" for
+// "linux/arm/v7".
+//
+// Linux platforms use the GNU C library, unless the platform has the "musl"
+// OS feature. An error is returned when the platform has no triplet.
+func ToTriple(platform specs.Platform) (string, error) {
+	p := Normalize(platform)
+	arch, ok := tripleArchs[p.Architecture]
+	if !ok {
+		return "", fmt.Errorf("%q: %w: no GNU target triplet", FormatAll(p), ErrUnknownPlatform)
+	}
+	if p.Architecture == "arm" {
+		switch p.Variant {
+		case "v5":
+			arch = "armv5te"
+		case "v8":
+			arch = "armv8l"
+		default:
+			arch += p.Variant
+		}
+	}
+
+	switch p.OS {
+	case "linux":
+		abi := "gnu"
+		if slices.Contains(p.OSFeatures, OSFeatureMusl) {
+			abi = "musl"
+		}
+		switch p.Architecture {
+		case "arm":
+			abi += "eabi"
+			if p.Variant != "v5" {
+				abi += "hf"
+			}
+		case "mips64", "mips64le":
+			abi += "abi64"
+		}
+		return arch + "-linux-" + abi, nil
+	case "android":
+		if p.Architecture == "arm" {
+			return arch + "-linux-androideabi", nil
+		}
+		return arch + "-linux-android", nil
+	case "darwin":
+		return arch + "-apple-darwin", nil
+	case "windows":
+		return arch + "-w64-mingw32", nil
+	case "freebsd", "netbsd", "openbsd":
+		return arch + "-unknown-" + p.OS, nil
+	}
+	return "", fmt.Errorf("%q: %w: no GNU target triplet", FormatAll(p), ErrUnknownPlatform)
+}
+
+// FromTriple returns the normalized platform of a GNU target triplet, such as
+// "x86_64-linux-gnu" or "armv7-unknown-linux-gnueabihf".
+//
+// The vendor is optional and ignored. The ABI may be one of gnu, musl,
+// gnueabi, gnueabihf, gnuabi64, musleabi, musleabihf, muslabi64, android or
+// androideabi. A musl
+// ABI adds the "musl" OS feature, an android ABI the "android" OS. Without a
+// version in the architecture, the ABI determines the variant of arm as with
+// "armhf" and "armel".
+func FromTriple(triple string) (specs.Platform, error) {
+	parts := strings.Split(strings.ToLower(triple), "-")
+	if len(parts) < 2 || len(parts) > 4 || slices.Contains(parts, "") {
+		return specs.Platform{}, fmt.Errorf("%q: %w: expected arch-[vendor-]os[-abi]", triple, errInvalidArgument)
+	}
+	// The vendor is the second of four parts, or of three parts when the
+	// last one is the operating system, as in "aarch64-redhat-linux".
+	arch, sys, abi := parts[0], parts[1], ""
+	switch len(parts) {
+	case 3:
+		if _, ok := tripleOS(parts[2]); ok {
+			sys = parts[2]
+		} else {
+			abi = parts[2]
+		}
+	case 4:
+		sys, abi = parts[2], parts[3]
+	}
+
+	var p specs.Platform
+	goos, ok := tripleOS(sys)
+	if !ok {
+		return specs.Platform{}, fmt.Errorf("%q: %w %q", triple, ErrInvalidOS, sys)
+	}
+	p.OS = goos
+	if goos == "linux" {
+		switch abi {
+		case "", "gnu", "gnueabi", "gnueabihf", "gnuabi64":
+		case "musl", "musleabi", "musleabihf", "muslabi64":
+			p.OSFeatures = []string{OSFeatureMusl}
+		case "android", "androideabi":
+			p.OS = "android"
+		default:
+			return specs.Platform{}, fmt.Errorf("%q: %w: unknown ABI %q", triple, ErrInvalidOS, abi)
+		}
+	}
+
+	switch {
+	case arch == "i386", arch == "i486", arch == "i586", arch == "i686":
+		p.Architecture = "386"
+	case arch == "loongarch64":
+		p.Architecture = "loong64"
+	case arch == "mipsel":
+		p.Architecture = "mipsle"
+	case arch == "mips64el":
+		p.Architecture = "mips64le"
+	case arch == "powerpc64":
+		p.Architecture = "ppc64"
+	case arch == "arm":
+		// Android requires ARMv7, and its "androideabi" ABI is not armel.
+		if strings.HasSuffix(abi, "eabi") && p.OS != "android" {
+			p.Architecture, p.Variant = normalizeArch("armel", "")
+		} else {
+			p.Architecture, p.Variant = normalizeArch("armhf", "")
+		}
+	case strings.HasPrefix(arch, "armv"):
+		// Drop the profile and suffixes, as in armv7a, armv7l or armv5te.
+		version := strings.TrimLeft(arch[len("armv"):], "0123456789")
+		version = arch[len("armv") : len(arch)-len(version)]
+		p.Architecture, p.Variant = normalizeArch("arm", version)
+		if version == "" {
+			return specs.Platform{}, fmt.Errorf("%q: %w %q", triple, ErrInvalidArchitecture, arch)
+		}
+	default:
+		p.Architecture, p.Variant = normalizeArch(arch, "")
+	}
+	if !isKnownArch(p.Architecture) {
+		return specs.Platform{}, fmt.Errorf("%q: %w %q", triple, ErrInvalidArchitecture, arch)
+	}
+	return Normalize(p), nil
+}
+
+// tripleOS returns the normalized operating system of the system component
+// of a GNU target triplet, which may include a version as in "darwin23.1.0".
+func tripleOS(sys string) (string, bool) {
+	switch sys = strings.TrimRight(sys, "0123456789."); sys {
+	case "linux":
+		return "linux", true
+	case "mingw", "windows":
+		return "windows", true
+	}
+	goos := normalizeOS(sys)
+	return goos, sys != "" && isKnownOS(goos)
+}
@@ -0,0 +1,143 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"errors"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestTriple(t *testing.T) {
+	for _, tc := range []struct {
+		platform string
+		triple   string
+	}{
+		{"linux/amd64", "x86_64-linux-gnu"},
+		{"linux/386", "i686-linux-gnu"},
+		{"linux/arm64", "aarch64-linux-gnu"},
+		{"linux/arm/v7", "armv7-linux-gnueabihf"},
+		{"linux/arm/v6", "armv6-linux-gnueabihf"},
+		{"linux/arm/v5", "armv5te-linux-gnueabi"},
+		{"linux/arm/v8", "armv8l-linux-gnueabihf"},
+		{"linux/ppc64le", "powerpc64le-linux-gnu"},
+		{"linux/s390x", "s390x-linux-gnu"},
+		{"linux/riscv64", "riscv64-linux-gnu"},
+		{"linux/mips64le", "mips64el-linux-gnuabi64"},
+		{"linux(+musl)/mips64le", "mips64el-linux-muslabi64"},
+		{"linux/mips64", "mips64-linux-gnuabi64"},
+		{"linux/loong64", "loongarch64-linux-gnu"},
+		{"linux(+musl)/amd64", "x86_64-linux-musl"},
+		{"linux(+musl)/arm/v7", "armv7-linux-musleabihf"},
+		{"android/arm64", "aarch64-linux-android"},
+		{"android/arm/v7", "armv7-linux-androideabi"},
+		{"darwin/arm64", "aarch64-apple-darwin"},
+		{"windows/amd64", "x86_64-w64-mingw32"},
+		{"freebsd/amd64", "x86_64-unknown-freebsd"},
+	} {
+		t.Run(tc.platform, func(t *testing.T) {
+			triple, err := ToTriple(MustParse(tc.platform))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if triple != tc.triple {
+				t.Errorf("ToTriple(%q) = %q, expected %q", tc.platform, triple, tc.triple)
+			}
+
+			p, err := FromTriple(tc.triple)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(p, MustParse(tc.platform)) {
+				t.Errorf("FromTriple(%q) = %q, expected %q", tc.triple, FormatAll(p), tc.platform)
+			}
+		})
+	}
+}
+
+func TestFromTriple(t *testing.T) {
+	for _, tc := range []struct {
+		triple   string
+		expected string
+	}{
+		{"x86_64-pc-linux-gnu", "linux/amd64"},
+		{"x86_64-unknown-linux-musl", "linux(+musl)/amd64"},
+		{"i386-linux-gnu", "linux/386"},
+		{"arm-linux-gnueabihf", "linux/arm/v7"},
+		{"arm-linux-gnueabi", "linux/arm/v6"},
+		{"armv7a-unknown-linux-gnueabihf", "linux/arm/v7"},
+		{"armv7l-linux-gnueabihf", "linux/arm/v7"},
+		{"armv6l-linux-gnueabihf", "linux/arm/v6"},
+		{"arm64-apple-darwin", "darwin/arm64"},
+		{"aarch64-apple-darwin23.1.0", "darwin/arm64"},
+		{"x86_64-unknown-freebsd14.0", "freebsd/amd64"},
+		{"x86_64-pc-windows-msvc", "windows/amd64"},
+		{"powerpc64-linux-gnu", "linux/ppc64"},
+		{"riscv64-unknown-linux-gnu", "linux/riscv64"},
+		{"x86_64-alpine-linux-musl", "linux(+musl)/amd64"},
+		{"s390x-ibm-linux-gnu", "linux/s390x"},
+		{"aarch64-redhat-linux", "linux/arm64"},
+		{"x86_64-pc-linux", "linux/amd64"},
+		{"aarch64-linux-android", "android/arm64"},
+		{"mips64el-unknown-linux-gnuabi64", "linux/mips64le"},
+		{"arm-linux-androideabi", "android/arm/v7"},
+		{"armv7a-linux-androideabi", "android/arm/v7"},
+	} {
+		t.Run(tc.triple, func(t *testing.T) {
+			p, err := FromTriple(tc.triple)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(p, MustParse(tc.expected)) {
+				t.Errorf("FromTriple(%q) = %q, expected %q", tc.triple, FormatAll(p), tc.expected)
+			}
+		})
+	}
+}
+
+func TestTripleErrors(t *testing.T) {
+	for _, tc := range []struct {
+		platform specs.Platform
+		reason   error
+	}{
+		{specs.Platform{OS: "js", Architecture: "wasm"}, ErrUnknownPlatform},
+		{specs.Platform{OS: "plan9", Architecture: "amd64"}, ErrUnknownPlatform},
+	} {
+		if _, err := ToTriple(tc.platform); !errors.Is(err, tc.reason) {
+			t.Errorf("ToTriple(%q): expected %v, got %v", Format(tc.platform), tc.reason, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		triple string
+		reason error
+	}{
+		{"x86_64", errInvalidArgument},
+		{"x86_64--linux-gnu", errInvalidArgument},
+		{"x86_64-pc-linux-gnu-extra", errInvalidArgument},
+		{"xtensa-linux-gnu", ErrInvalidArchitecture},
+		{"armvx-linux-gnueabihf", ErrInvalidArchitecture},
+		{"x86_64-linux-gnux32", ErrInvalidOS},
+		{"x86_64-unknown-haiku", ErrInvalidOS},
+		{"x86_64-redhat-haiku-gnu", ErrInvalidOS},
+	} {
+		if _, err := FromTriple(tc.triple); !errors.Is(err, tc.reason) {
+			t.Errorf("FromTriple(%q): expected %v, got %v", tc.triple, tc.reason, err)
+		}
+	}
+}
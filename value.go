@@ -0,0 +1,155 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"encoding/json"
+	"strings"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Value is a platform which can be used as a [flag.Value] and encoded as text
+// or JSON, using the platform specifier syntax of [Parse] and [FormatAll].
+// This allows platforms to be used in flag sets and configuration files.
+//
+// The zero value is encoded as an empty string.
+type Value specs.Platform
+
+// String returns the platform specifier, including the OS version and
+// features.
+func (v Value) String() string {
+	if v.OS == "" {
+		return ""
+	}
+	return FormatAll(specs.Platform(v))
+}
+
+// Set parses the platform specifier.
+func (v *Value) Set(s string) error {
+	p, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*v = Value(p)
+	return nil
+}
+
+// MarshalText returns the platform specifier.
+func (v Value) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText parses the platform specifier. An empty specifier results in
+// the zero value.
+func (v *Value) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*v = Value{}
+		return nil
+	}
+	return v.Set(string(text))
+}
+
+// MarshalJSON encodes the platform specifier as a JSON string.
+func (v Value) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+// UnmarshalJSON decodes the platform specifier from a JSON string.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// Values is a list of platforms which can be used as a [flag.Value] and
+// encoded as text or JSON.
+//
+// As a flag, each use appends to the list and may provide several specifiers,
+// separated as with [ParseList]. As text, the specifiers are separated by
+// commas. As JSON, the list is an array of specifiers, although a single
+// string of separated specifiers is also accepted. Unlike [ParseList],
+// equivalent specifiers are kept, so that text and JSON decode alike.
+type Values []specs.Platform
+
+// String returns the comma separated platform specifiers. Zero platforms are
+// skipped, as they have no specifier.
+func (v Values) String() string {
+	specifiers := make([]string, 0, len(v))
+	for _, p := range v {
+		if s := Value(p).String(); s != "" {
+			specifiers = append(specifiers, s)
+		}
+	}
+	return strings.Join(specifiers, ",")
+}
+
+// Set parses the platform specifiers and appends them to the list.
+func (v *Values) Set(s string) error {
+	platforms, err := parseList(s)
+	if err != nil {
+		return err
+	}
+	*v = append(*v, platforms...)
+	return nil
+}
+
+// MarshalText returns the comma separated platform specifiers.
+func (v Values) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText parses the separated platform specifiers, replacing the list.
+func (v *Values) UnmarshalText(text []byte) error {
+	platforms, err := parseList(string(text))
+	if err != nil {
+		return err
+	}
+	*v = platforms
+	return nil
+}
+
+// MarshalJSON encodes the platform specifiers as a JSON array of strings.
+func (v Values) MarshalJSON() ([]byte, error) {
+	specifiers := make([]Value, len(v))
+	for i, p := range v {
+		specifiers[i] = Value(p)
+	}
+	return json.Marshal(specifiers)
+}
+
+// UnmarshalJSON decodes the platform specifiers from a JSON array of strings
+// or a single string of separated specifiers, replacing the list.
+func (v *Values) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return v.UnmarshalText([]byte(s))
+	}
+
+	var specifiers []Value
+	if err := json.Unmarshal(data, &specifiers); err != nil {
+		return err
+	}
+	platforms := make(Values, len(specifiers))
+	for i, p := range specifiers {
+		platforms[i] = specs.Platform(p)
+	}
+	*v = platforms
+	return nil
+}
@@ -0,0 +1,174 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package platforms
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var (
+	_ flag.Value               = (*Value)(nil)
+	_ encoding.TextMarshaler   = Value{}
+	_ encoding.TextUnmarshaler = (*Value)(nil)
+	_ json.Marshaler           = Value{}
+	_ json.Unmarshaler         = (*Value)(nil)
+	_ flag.Value               = (*Values)(nil)
+	_ encoding.TextMarshaler   = Values{}
+	_ encoding.TextUnmarshaler = (*Values)(nil)
+	_ json.Marshaler           = Values{}
+	_ json.Unmarshaler         = (*Values)(nil)
+)
+
+func TestValueRoundTrip(t *testing.T) {
+	for _, p := range []specs.Platform{
+		{},
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm", Variant: "v6"},
+		{OS: "linux", Architecture: "arm64", Variant: "v8.2"},
+		{OS: "windows", Architecture: "amd64", OSVersion: "10.0.17763+build.42", OSFeatures: []string{"win32k"}},
+		{OS: "linux", Architecture: "amd64", OSFeatures: []string{"gpu", "simd"}},
+	} {
+		t.Run(Value(p).String(), func(t *testing.T) {
+			text, err := Value(p).MarshalText()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fromText Value
+			if err := fromText.UnmarshalText(text); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(specs.Platform(fromText), p) {
+				t.Errorf("text round trip: %#v != %#v", fromText, p)
+			}
+
+			data, err := json.Marshal(Value(p))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fromJSON Value
+			if err := json.Unmarshal(data, &fromJSON); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(specs.Platform(fromJSON), p) {
+				t.Errorf("JSON round trip of %s: %#v != %#v", data, fromJSON, p)
+			}
+		})
+	}
+}
+
+func TestValueFlag(t *testing.T) {
+	var (
+		platform  Value
+		platforms Values
+	)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&platform, "platform", "platform")
+	fs.Var(&platforms, "platforms", "platforms")
+	if err := fs.Parse([]string{"--platform", "linux(+gpu)/arm64", "--platforms", "linux/amd64,linux/arm64", "--platforms", "linux/arm/v7"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (Value{OS: "linux", Architecture: "arm64", OSFeatures: []string{"gpu"}}); !reflect.DeepEqual(platform, expected) {
+		t.Errorf("unexpected platform: %#v != %#v", platform, expected)
+	}
+	if s := platforms.String(); s != "linux/amd64,linux/arm64,linux/arm/v7" {
+		t.Errorf("unexpected platforms: %s", s)
+	}
+
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse([]string{"--platform", "linux/&arm"}); err == nil {
+		t.Errorf("should have received an error")
+	}
+}
+
+func TestValuesJSON(t *testing.T) {
+	var config struct {
+		Platform  Value  `json:"platform"`
+		Platforms Values `json:"platforms"`
+	}
+	if err := json.Unmarshal([]byte(`{"platform":"windows(10.0.20348)/amd64","platforms":["linux/amd64","linux(+gpu)/arm64"]}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	expected := Values{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm64", OSFeatures: []string{"gpu"}},
+	}
+	if !reflect.DeepEqual(config.Platforms, expected) {
+		t.Errorf("unexpected platforms: %#v != %#v", config.Platforms, expected)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); s != `{"platform":"windows(10.0.20348)/amd64","platforms":["linux/amd64","linux(+gpu)/arm64"]}` {
+		t.Errorf("unexpected JSON: %s", s)
+	}
+
+	if err := json.Unmarshal([]byte(`{"platforms":"linux/amd64, linux/arm64"}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if s := config.Platforms.String(); s != "linux/amd64,linux/arm64" {
+		t.Errorf("unexpected platforms: %s", s)
+	}
+
+	if err := json.Unmarshal([]byte(`{"platforms":["linux/&arm"]}`), &config); !errors.Is(err, ErrInvalidArchitecture) {
+		t.Errorf("expected %v, got %v", ErrInvalidArchitecture, err)
+	}
+}
+
+func TestValuesText(t *testing.T) {
+	// Equivalent specifiers are kept as with JSON arrays.
+	var text, array Values
+	if err := text.UnmarshalText([]byte("linux/arm64,linux/aarch64/v8")); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`["linux/arm64","linux/aarch64/v8"]`), &array); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(text, array) {
+		t.Errorf("text and JSON decode differently: %#v != %#v", text, array)
+	}
+	if len(text) != 2 {
+		t.Errorf("expected 2 platforms, got %d", len(text))
+	}
+
+	var flagged Values
+	if err := flagged.Set("linux/amd64 linux/x86_64"); err != nil {
+		t.Fatal(err)
+	}
+	if s := flagged.String(); s != "linux/amd64,linux/amd64" {
+		t.Errorf("unexpected platforms: %s", s)
+	}
+
+	// Zero platforms have no specifier and are skipped.
+	v := Values{{}, {OS: "linux", Architecture: "amd64"}, {}}
+	if s := v.String(); s != "linux/amd64" {
+		t.Errorf("unexpected platforms: %q", s)
+	}
+	if s := (Values{{}}).String(); s != "" {
+		t.Errorf("unexpected platforms: %q", s)
+	}
+}